@@ -9,6 +9,8 @@ import (
 const ConvertDefaultSamplingRate = 44100
 const ConvertDefaultBitDepth = 8
 const ConvertDefaultSpeedFactor = 1.0
+const AccelerateDefaultMinPilotPulses = 1000
+const AccelerateDefaultMaxPause = 1000
 
 type Cli struct {
 	tapeService *tape.Service
@@ -20,6 +22,7 @@ func NewCli(tapeService *tape.Service) *Cli {
 		tapeService: tapeService,
 		commands: []Command{
 			&Convert{},
+			&Devices{},
 			&Info{},
 			&Play{},
 		},
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"github.com/TiBeN/tzx-player/tape"
+	"math"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 type Convert struct {
@@ -15,16 +18,27 @@ func (c *Convert) Name() string {
 }
 
 func (c *Convert) Description() string {
-	return "Convert TZX tape to an audio PCM Wav file"
+	return "Convert TZX tape to an audio PCM Wav file, or to a PZX file"
 }
 
 func (c *Convert) Usage() string {
 	usage := fmt.Sprintf("    Args:\n")
-	usage += fmt.Sprintf("      tzx-player convert INPUT_TZX_FILE OUTPUT_WAV_FILE\n")
+	usage += fmt.Sprintf("      tzx-player convert INPUT_TZX_FILE OUTPUT_FILE\n")
+	usage += fmt.Sprintf("      The output is a PZX file if OUTPUT_FILE ends with .pzx, a WAV file otherwise.\n")
+	usage += fmt.Sprintf("      Sampling rate, bit depth, speed factor, band limiting and positions apply to WAV files only.\n")
 	usage += fmt.Sprintf("    Options:\n")
 	usage += fmt.Sprintf("      %-20sSampling rate (default: %d)\n", "-s int", ConvertDefaultSamplingRate)
-	usage += fmt.Sprintf("      %-20sBit depth (default: %d, possibles values: 8 or 16)\n", "-b int", ConvertDefaultBitDepth)
+	usage += fmt.Sprintf("      %-20sBit depth (default: %d, possibles values: 8, 16, 24 or 32 for 32 bit float)\n", "-b int", ConvertDefaultBitDepth)
 	usage += fmt.Sprintf("      %-20sSpeed factor: multiply the speed of the tones (experimental) (default: %.1f)\n", "-f float", ConvertDefaultSpeedFactor)
+	usage += fmt.Sprintf("      %-20sAppend a non-standard tail of 32 ONE bits after standard and turbo speed data blocks\n", "--tail")
+	usage += fmt.Sprintf("      %-20sSmooth the edges of the signal to reduce aliasing at low sampling rates\n", "--band-limited")
+	usage += fmt.Sprintf("      %-20sAccelerated load: shorten pilot tones and long pauses (same as --min-pilot %d --max-pause %d)\n", "--accelerate", AccelerateDefaultMinPilotPulses, AccelerateDefaultMaxPause)
+	usage += fmt.Sprintf("      %-20sShorten the pilot tones of data blocks to this number of pulses\n", "--min-pilot int")
+	usage += fmt.Sprintf("      %-20sLimit the pauses to this duration in ms. Pauses stopping the tape are kept\n", "--max-pause int")
+	usage += fmt.Sprintf("      %-20sStart position: block:N, group:NAME or time as [h:]m:ss (default: beginning of the tape)\n", "--from pos")
+	usage += fmt.Sprintf("      %-20sEnd position, the block or group is included: block:N, group:NAME or time as [h:]m:ss (default: end of the tape)\n", "--to pos")
+	usage += fmt.Sprintf("      %-20sVideo standard of the computer C64 TAP files are played to (default: from the file, possibles values: pal or ntsc)\n", "--video string")
+	usage += fmt.Sprintf("      %-20sEntry to choose at Select blocks, starting from 1 (default: Select blocks are ignored)\n", "--select int")
 	return usage
 }
 
@@ -32,9 +46,12 @@ func (c *Convert) Exec(service *tape.Service, args []string) error {
 	var tzxFile string
 	var outputFile string
 	var err error
-	samplingRate := ConvertDefaultSamplingRate
-	bitDepth := ConvertDefaultBitDepth
-	speedFactor := ConvertDefaultSpeedFactor
+	options := tape.ReaderOptions{
+		SamplingRate: ConvertDefaultSamplingRate,
+		BitDepth:     ConvertDefaultBitDepth,
+		SpeedFactor:  ConvertDefaultSpeedFactor,
+		Selection:    tape.SelectionNone,
+	}
 
 	// Parse args
 	for i := 0; i < len(args); i++ {
@@ -43,7 +60,7 @@ func (c *Convert) Exec(service *tape.Service, args []string) error {
 			if i == len(args)-1 {
 				return errors.New("missing -s argument")
 			}
-			samplingRate, err = strconv.Atoi(args[i+1])
+			options.SamplingRate, err = strconv.Atoi(args[i+1])
 			if err != nil {
 				return errors.New("-s argument is not a valid number")
 			}
@@ -52,7 +69,7 @@ func (c *Convert) Exec(service *tape.Service, args []string) error {
 			if i == len(args)-1 {
 				return fmt.Errorf("missing -b argument")
 			}
-			bitDepth, err = strconv.Atoi(args[i+1])
+			options.BitDepth, err = strconv.Atoi(args[i+1])
 			if err != nil {
 				return errors.New("-s argument is not a valid number")
 			}
@@ -61,11 +78,72 @@ func (c *Convert) Exec(service *tape.Service, args []string) error {
 			if i == len(args)-1 {
 				return fmt.Errorf("missing -f argument")
 			}
-			speedFactor, err = strconv.ParseFloat(args[i+1], 64)
-			if err != nil {
+			options.SpeedFactor, err = strconv.ParseFloat(args[i+1], 64)
+			if err != nil || !(options.SpeedFactor > 0) || math.IsInf(options.SpeedFactor, 1) {
 				return errors.New("-f argument is not a valid number")
 			}
 			i++
+		case "--select":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing --select argument")
+			}
+			options.Selection, err = strconv.Atoi(args[i+1])
+			if err != nil || options.Selection < 1 {
+				return errors.New("--select argument is not a valid selection number")
+			}
+			i++
+		case "--video":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing --video argument")
+			}
+			switch args[i+1] {
+			case "pal":
+				options.VideoStandard = tape.VideoStandardPal
+			case "ntsc":
+				options.VideoStandard = tape.VideoStandardNtsc
+			default:
+				return errors.New("--video argument is not a valid video standard")
+			}
+			i++
+		case "--tail":
+			options.CompatibilityTail = true
+		case "--band-limited":
+			options.BandLimited = true
+		case "--accelerate":
+			options.MinPilotPulses = AccelerateDefaultMinPilotPulses
+			options.MaxPauseDuration = AccelerateDefaultMaxPause
+		case "--min-pilot":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing --min-pilot argument")
+			}
+			options.MinPilotPulses, err = strconv.Atoi(args[i+1])
+			if err != nil || options.MinPilotPulses < 1 {
+				return errors.New("--min-pilot argument is not a valid number of pulses")
+			}
+			i++
+		case "--from", "--to":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing %s argument", args[i])
+			}
+			position, err := tape.ParsePosition(args[i+1])
+			if err != nil {
+				return fmt.Errorf("%s argument: %s", args[i], err.Error())
+			}
+			if args[i] == "--from" {
+				options.From = &position
+			} else {
+				options.To = &position
+			}
+			i++
+		case "--max-pause":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing --max-pause argument")
+			}
+			options.MaxPauseDuration, err = strconv.Atoi(args[i+1])
+			if err != nil || options.MaxPauseDuration < 1 {
+				return errors.New("--max-pause argument is not a valid duration")
+			}
+			i++
 		default:
 			if tzxFile == "" {
 				tzxFile = args[i]
@@ -75,7 +153,11 @@ func (c *Convert) Exec(service *tape.Service, args []string) error {
 		}
 	}
 
-	generationTime, err := service.ConvertToWavFile(tzxFile, outputFile, samplingRate, bitDepth, speedFactor)
+	convert := service.ConvertToWavFile
+	if strings.ToLower(filepath.Ext(outputFile)) == ".pzx" {
+		convert = service.ConvertToPzxFile
+	}
+	generationTime, err := convert(tzxFile, outputFile, options)
 
 	if err == nil {
 		fmt.Printf("Generation time: %s\n", generationTime)
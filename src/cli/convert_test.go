@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/TiBeN/tzx-player/tape"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Blocks of the test tapes
+var (
+	selectBlock = []byte{0x28, 0x13, 0x00, 0x02,
+		0x01, 0x00, 0x06, 'S', 'i', 'd', 'e', ' ', 'A',
+		0x02, 0x00, 0x06, 'S', 'i', 'd', 'e', ' ', 'B'}
+	toneA = []byte{0x12, 0xe8, 0x03, 0x0a, 0x00}
+	toneB = []byte{0x12, 0xd0, 0x07, 0x0a, 0x00}
+)
+
+// convert writes a TZX file made of the given blocks, converts it with the
+// given options and returns the content of the WAV file
+func convert(t *testing.T, options []string, blocks ...[]byte) ([]byte, error) {
+	t.Helper()
+	dir := t.TempDir()
+	tzxFile := filepath.Join(dir, "test.tzx")
+	wavFile := filepath.Join(dir, "test.wav")
+	content := []byte("ZXTape!\x1a\x01\x14")
+	for _, b := range blocks {
+		content = append(content, b...)
+	}
+	if err := os.WriteFile(tzxFile, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Convert{}
+	if err := c.Exec(tape.NewService(), append([]string{tzxFile, wavFile}, options...)); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(wavFile)
+}
+
+func TestConvertSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []string
+		expected [][]byte
+	}{
+		{"select blocks ignored", nil, [][]byte{toneA, toneB}},
+		{"first entry", []string{"--select", "1"}, [][]byte{toneA, toneB}},
+		{"second entry", []string{"--select", "2"}, [][]byte{toneB}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wav, err := convert(t, test.options, selectBlock, toneA, toneB)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := convert(t, nil, test.expected...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(wav, expected) {
+				t.Errorf("WAV file of %d bytes differs from the expected one of %d bytes", len(wav), len(expected))
+			}
+		})
+	}
+}
+
+func TestConvertSelectErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []string
+		err     string
+	}{
+		{"missing selection", []string{"--select"}, "missing --select argument"},
+		{"zero selection", []string{"--select", "0"}, "not a valid selection number"},
+		{"invalid selection", []string{"--select", "A"}, "not a valid selection number"},
+		{"undefined selection", []string{"--select", "3"}, "selection 3 does not exist"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := convert(t, test.options, selectBlock, toneA, toneB)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestConvertSpeedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []string
+		err     string
+	}{
+		{"missing speed factor", []string{"-f"}, "missing -f argument"},
+		{"zero speed factor", []string{"-f", "0"}, "-f argument is not a valid number"},
+		{"negative speed factor", []string{"-f", "-1"}, "-f argument is not a valid number"},
+		{"infinite speed factor", []string{"-f", "Inf"}, "-f argument is not a valid number"},
+		{"NaN speed factor", []string{"-f", "NaN"}, "-f argument is not a valid number"},
+		{"missing minimum pilot", []string{"--min-pilot"}, "missing --min-pilot argument"},
+		{"zero minimum pilot", []string{"--min-pilot", "0"}, "not a valid number of pulses"},
+		{"missing maximum pause", []string{"--max-pause"}, "missing --max-pause argument"},
+		{"negative maximum pause", []string{"--max-pause", "-100"}, "not a valid duration"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := convert(t, test.options, toneA)
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
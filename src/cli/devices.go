@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/TiBeN/tzx-player/tape"
+)
+
+type Devices struct {
+}
+
+func (c *Devices) Name() string {
+	return "devices"
+}
+
+func (c *Devices) Description() string {
+	return "List audio host APIs and output devices"
+}
+
+func (c *Devices) Usage() string {
+	usage := fmt.Sprintf("    Args:\n")
+	usage += fmt.Sprintf("      tzx-player devices\n")
+	return usage
+}
+
+func (c *Devices) Exec(service *tape.Service, args []string) error {
+	hostApis, err := service.Devices()
+	if err != nil {
+		return err
+	}
+
+	for _, hostApi := range hostApis {
+		defaultMark := ""
+		if hostApi.Default {
+			defaultMark = " (default)"
+		}
+		fmt.Printf("%s%s\n", hostApi.Name, defaultMark)
+
+		for _, device := range hostApi.Devices {
+			defaultMark = ""
+			if device.Default {
+				defaultMark = " (default)"
+			}
+			fmt.Printf(
+				"  %3d : %s%s - %d channels, %.0f Hz, latency %s to %s\n",
+				device.Index,
+				device.Name,
+				defaultMark,
+				device.MaxOutputChannels,
+				device.DefaultSampleRate,
+				device.DefaultLowLatency,
+				device.DefaultHighLatency,
+			)
+		}
+	}
+
+	return nil
+}
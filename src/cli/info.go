@@ -20,20 +20,44 @@ func (c *Info) Description() string {
 func (c *Info) Usage() string {
 	usage := fmt.Sprintf("    Args:\n")
 	usage += fmt.Sprintf("      tzx-player info INPUT_TZX_FILE\n")
+	usage += fmt.Sprintf("    Options:\n")
+	usage += fmt.Sprintf("      %-20sExtract Custom Info blocks contents (POKEs, instructions, pictures...) into the given directory\n", "-x dir")
 	return usage
 }
 
 func (c *Info) Exec(service *tape.Service, args []string) error {
-	if len(args) < 1 {
+	var tzxFile string
+	var extractDir string
+
+	// Parse args
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-x":
+			if i == len(args)-1 {
+				return errors.New("missing -x argument")
+			}
+			extractDir = args[i+1]
+			i++
+		default:
+			if tzxFile == "" {
+				tzxFile = args[i]
+			}
+		}
+	}
+
+	if tzxFile == "" {
 		return errors.New("TZX file not specified")
 	}
 
-	info, err := service.Info(args[0])
+	info, err := service.Info(tzxFile)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("%-40s: %s\n", "TZX Tape Version", info.Version)
+	fmt.Printf("%-40s: %s\n", "Tape format", info.Format)
+	if info.Version != "" {
+		fmt.Printf("%-40s: %s\n", "Tape Version", info.Version)
+	}
 
 	for _, block := range info.Blocks {
 		fmt.Println("")
@@ -42,5 +66,16 @@ func (c *Info) Exec(service *tape.Service, args []string) error {
 		}
 	}
 
+	if extractDir != "" {
+		files, err := service.ExtractCustomInfos(tzxFile, extractDir)
+		if err != nil {
+			return err
+		}
+		fmt.Println("")
+		for _, file := range files {
+			fmt.Printf("%-40s: %s\n", "Extracted file", file)
+		}
+	}
+
 	return nil
 }
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"github.com/TiBeN/tzx-player/tape"
 	"github.com/eiannone/keyboard"
+	"math"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -30,9 +32,21 @@ func (c *Play) Usage() string {
 	usage += fmt.Sprintln("      tzx-player play INPUT_TZX_FILE")
 	usage += fmt.Sprintln("    Options:")
 	usage += fmt.Sprintf("      %-20sSampling rate (default: %d)\n", "-s int", ConvertDefaultSamplingRate)
-	usage += fmt.Sprintf("      %-20sBit depth (default: %d, possibles values: 8 or 16)\n", "-b int", ConvertDefaultBitDepth)
+	usage += fmt.Sprintf("      %-20sBit depth (default: %d, possibles values: 8, 16, 24 or 32 for 32 bit float)\n", "-b int", ConvertDefaultBitDepth)
 	usage += fmt.Sprintf("      %-20sEnable tape remote control using a GPIO device. Support only Numato labs GPIO Modules for now. Exemple: -g /dev/ttyACM0:9600:1\n", "-g port:baud:ionb")
 	usage += fmt.Sprintf("      %-20sSpeed factor: multiply the speed of the tones (experimental) (default: %.1f)\n", "-f float", ConvertDefaultSpeedFactor)
+	usage += fmt.Sprintf("      %-20sAppend a non-standard tail of 32 ONE bits after standard and turbo speed data blocks\n", "--tail")
+	usage += fmt.Sprintf("      %-20sSmooth the edges of the signal to reduce aliasing at low sampling rates\n", "--band-limited")
+	usage += fmt.Sprintf("      %-20sAccelerated load: shorten pilot tones and long pauses (same as --min-pilot %d --max-pause %d)\n", "--accelerate", AccelerateDefaultMinPilotPulses, AccelerateDefaultMaxPause)
+	usage += fmt.Sprintf("      %-20sShorten the pilot tones of data blocks to this number of pulses\n", "--min-pilot int")
+	usage += fmt.Sprintf("      %-20sLimit the pauses to this duration in ms. Pauses stopping the tape are kept\n", "--max-pause int")
+	usage += fmt.Sprintf("      %-20sStart position: block:N, group:NAME or time as [h:]m:ss (default: beginning of the tape)\n", "--from pos")
+	usage += fmt.Sprintf("      %-20sEnd position, the block or group is included: block:N, group:NAME or time as [h:]m:ss (default: end of the tape)\n", "--to pos")
+	usage += fmt.Sprintf("      %-20sMachine model. 48k stops the tape at 'Stop the tape if in 48K mode' blocks (default: 128k, possibles values: 48k or 128k)\n", "-m string")
+	usage += fmt.Sprintf("      %-20sVideo standard of the computer C64 TAP files are played to (default: from the file, possibles values: pal or ntsc)\n", "--video string")
+	usage += fmt.Sprintf("      %-20sOutput device: index or part of the name as listed by the devices command (default: default output device)\n", "-d string")
+	usage += fmt.Sprintf("      %-20sAudio buffer size in samples (default: %d)\n", "--buffer int", tape.DefaultFramesPerBuffer)
+	usage += fmt.Sprintf("      %-20sOutput latency in ms (default: device default latency)\n", "--latency int")
 	usage += fmt.Sprintln("   Player control keystrokes:")
 	usage += fmt.Sprintln("       Space : Toggle play/pause")
 	usage += fmt.Sprintln("       Right arrow : Fast forward")
@@ -41,6 +55,13 @@ func (c *Play) Usage() string {
 	usage += fmt.Sprintln("       p : Pause")
 	usage += fmt.Sprintln("       s : Save current tape position")
 	usage += fmt.Sprintln("       g : Set tape to last saved position")
+	usage += fmt.Sprintln("       1-9 : Choose an entry when the tape stops at a Select block")
+	usage += fmt.Sprintln("       n : Next block")
+	usage += fmt.Sprintln("       b : Previous block")
+	usage += fmt.Sprintln("       h : Start of the current block")
+	usage += fmt.Sprintln("       N : Next group")
+	usage += fmt.Sprintln("       B : Previous group")
+	usage += fmt.Sprintln("       j : Go to the block number typed next, then Enter")
 
 	return usage
 }
@@ -49,9 +70,13 @@ func (c *Play) Exec(service *tape.Service, args []string) error {
 	var tzxFile string
 
 	var err error
-	samplingRate := ConvertDefaultSamplingRate
-	bitDepth := ConvertDefaultBitDepth
-	speedFactor := ConvertDefaultSpeedFactor
+	options := tape.ReaderOptions{
+		SamplingRate: ConvertDefaultSamplingRate,
+		BitDepth:     ConvertDefaultBitDepth,
+		SpeedFactor:  ConvertDefaultSpeedFactor,
+		Selection:    tape.SelectionInteractive,
+	}
+	var playerOptions tape.PlayerOptions
 	enableGpio := false
 	gpioPort := ""
 	gpioBaudRate := 0
@@ -64,7 +89,7 @@ func (c *Play) Exec(service *tape.Service, args []string) error {
 			if i == len(args)-1 {
 				return errors.New("missing -s argument")
 			}
-			samplingRate, err = strconv.Atoi(args[i+1])
+			options.SamplingRate, err = strconv.Atoi(args[i+1])
 			if err != nil {
 				return errors.New("-s argument is not a valid number")
 			}
@@ -73,7 +98,7 @@ func (c *Play) Exec(service *tape.Service, args []string) error {
 			if i == len(args)-1 {
 				return fmt.Errorf("missing -b argument")
 			}
-			bitDepth, err = strconv.Atoi(args[i+1])
+			options.BitDepth, err = strconv.Atoi(args[i+1])
 			if err != nil {
 				return errors.New("-s argument is not a valid number")
 			}
@@ -82,11 +107,62 @@ func (c *Play) Exec(service *tape.Service, args []string) error {
 			if i == len(args)-1 {
 				return fmt.Errorf("missing -f argument")
 			}
-			speedFactor, err = strconv.ParseFloat(args[i+1], 64)
-			if err != nil {
+			options.SpeedFactor, err = strconv.ParseFloat(args[i+1], 64)
+			if err != nil || !(options.SpeedFactor > 0) || math.IsInf(options.SpeedFactor, 1) {
 				return errors.New("-f argument is not a valid number")
 			}
 			i++
+		case "-m":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing -m argument")
+			}
+			switch args[i+1] {
+			case "48k":
+				options.Machine = tape.Machine48K
+			case "128k":
+				options.Machine = tape.Machine128K
+			default:
+				return errors.New("-m argument is not a valid machine model")
+			}
+			i++
+		case "--video":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing --video argument")
+			}
+			switch args[i+1] {
+			case "pal":
+				options.VideoStandard = tape.VideoStandardPal
+			case "ntsc":
+				options.VideoStandard = tape.VideoStandardNtsc
+			default:
+				return errors.New("--video argument is not a valid video standard")
+			}
+			i++
+		case "-d":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing -d argument")
+			}
+			playerOptions.Device = args[i+1]
+			i++
+		case "--buffer":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing --buffer argument")
+			}
+			playerOptions.FramesPerBuffer, err = strconv.Atoi(args[i+1])
+			if err != nil || playerOptions.FramesPerBuffer < 1 {
+				return errors.New("--buffer argument is not a valid buffer size")
+			}
+			i++
+		case "--latency":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing --latency argument")
+			}
+			latency, err := strconv.Atoi(args[i+1])
+			if err != nil || latency < 1 {
+				return errors.New("--latency argument is not a valid duration")
+			}
+			playerOptions.Latency = time.Duration(latency) * time.Millisecond
+			i++
 		case "-g":
 			enableGpio = true
 			if i == len(args)-1 {
@@ -107,6 +183,45 @@ func (c *Play) Exec(service *tape.Service, args []string) error {
 				return errors.New("-g argument: not a valid IO port number value")
 			}
 			i++
+		case "--tail":
+			options.CompatibilityTail = true
+		case "--band-limited":
+			options.BandLimited = true
+		case "--accelerate":
+			options.MinPilotPulses = AccelerateDefaultMinPilotPulses
+			options.MaxPauseDuration = AccelerateDefaultMaxPause
+		case "--min-pilot":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing --min-pilot argument")
+			}
+			options.MinPilotPulses, err = strconv.Atoi(args[i+1])
+			if err != nil || options.MinPilotPulses < 1 {
+				return errors.New("--min-pilot argument is not a valid number of pulses")
+			}
+			i++
+		case "--from", "--to":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing %s argument", args[i])
+			}
+			position, err := tape.ParsePosition(args[i+1])
+			if err != nil {
+				return fmt.Errorf("%s argument: %s", args[i], err.Error())
+			}
+			if args[i] == "--from" {
+				options.From = &position
+			} else {
+				options.To = &position
+			}
+			i++
+		case "--max-pause":
+			if i == len(args)-1 {
+				return fmt.Errorf("missing --max-pause argument")
+			}
+			options.MaxPauseDuration, err = strconv.Atoi(args[i+1])
+			if err != nil || options.MaxPauseDuration < 1 {
+				return errors.New("--max-pause argument is not a valid duration")
+			}
+			i++
 		default:
 			if tzxFile == "" {
 				tzxFile = args[i]
@@ -114,7 +229,7 @@ func (c *Play) Exec(service *tape.Service, args []string) error {
 		}
 	}
 
-	player, err := service.Play(tzxFile, samplingRate, bitDepth, speedFactor)
+	player, err := service.Play(tzxFile, options, playerOptions)
 	if err != nil {
 		return err
 	}
@@ -122,33 +237,109 @@ func (c *Play) Exec(service *tape.Service, args []string) error {
 
 	sigs := make(chan os.Signal, 1)
 
-	// Infos status bar
+	// quit unlocks the main thread. It doesn't block when a signal is pending.
+	quit := func() {
+		select {
+		case sigs <- syscall.SIGTERM:
+		default:
+		}
+	}
+
+	// Quit at the end of the tape, show audio errors
+	events, unsubscribe := player.Subscribe()
+	defer unsubscribe()
+	go func() {
+		for event := range events {
+			if event.Type == tape.EventError {
+				fmt.Printf("\r\033[KAudio error: %s\r\n", event.Err.Error())
+			}
+			if event.Type == tape.EventEndOfTape {
+				quit()
+				return
+			}
+		}
+	}()
+	if player.State() == tape.PlayerFinished {
+		quit()
+	}
+
+	// redraw requests the status bar to be drawn again
+	redraw := make(chan struct{}, 1)
+
+	// Block number typed by the user to go to, -1 when no number is typed
+	var blockInput atomic.Int64
+	blockInput.Store(-1)
+	setBlockInput := func(typed int64) {
+		blockInput.Store(typed)
+		select {
+		case redraw <- struct{}{}:
+		default:
+		}
+	}
+
+	// Infos status bar, drawn again at each event of the player and when a
+	// block number is typed. The position is the one of the EventPosition
+	// events, the other infos are updated at the other events.
+	statusEvents, statusUnsubscribe := player.Subscribe()
+	defer statusUnsubscribe()
 	go func() {
-		infosTicker := time.NewTicker(time.Duration(60) * time.Millisecond)
+		menuShown := false
+		playerInfos := player.Infos()
 		for {
-			<-infosTicker.C
-			playerInfos := player.Infos()
-			if !playerInfos.Playing {
-				sigs <- syscall.SIGTERM
+
+			// Show the entries of a Select block
+			if playerInfos.Selections == nil {
+				menuShown = false
+			} else if !menuShown {
+				fmt.Print("\r\033[KSelect a part of the tape:\r\n")
+				for i, description := range playerInfos.Selections {
+					fmt.Printf("  %d : %s\r\n", i+1, description)
+				}
+				menuShown = true
 			}
 
 			playStatus := "\u23F5"
 			if playerInfos.Pause {
 				playStatus = "\u23F8"
 			}
+			stopMessage := ""
+			if playerInfos.TapeStopped {
+				playStatus = "\u23F9"
+				stopMessage = " - Tape stopped, press Space to continue"
+			}
+			if typed := blockInput.Load(); typed == 0 {
+				stopMessage = " - Go to block: "
+			} else if typed > 0 {
+				stopMessage = fmt.Sprintf(" - Go to block: %d", typed)
+			}
 
 			totalTime := time.Unix(playerInfos.TotalSeconds, 0)
 			currentTime := time.Unix(playerInfos.PosSeconds, 0)
 
 			fmt.Printf(
-				"\r\033[K%s %s - %s / %s (%d%%) - Block: %s",
+				"\r\033[K%s %s - %s / %s (%d%%) - Block: %s%s",
 				playStatus,
 				filepath.Base(playerInfos.FileName),
 				currentTime.UTC().Format("15:04:05"),
 				totalTime.UTC().Format("15:04:05"),
 				playerInfos.PosPercent,
 				playerInfos.BlockInfo,
+				stopMessage,
 			)
+
+			select {
+			case event, ok := <-statusEvents:
+				if !ok {
+					return
+				}
+				if event.Type == tape.EventPosition {
+					playerInfos.PosSeconds = int64(event.Time / time.Second)
+					playerInfos.PosPercent = event.Percent
+				} else {
+					playerInfos = player.Infos()
+				}
+			case <-redraw:
+			}
 		}
 	}()
 
@@ -160,11 +351,60 @@ func (c *Play) Exec(service *tape.Service, args []string) error {
 		_ = keyboard.Close()
 	}()
 	go func() {
+		selection := 0
 		for {
 			char, key, err := keyboard.GetKey()
 			if err != nil {
 				panic(err)
 			}
+
+			// Select block entry choice. Digits are accumulated until the
+			// typed number can't be the beginning of another entry number
+			if selections := player.Infos().Selections; selections != nil {
+				if char >= '0' && char <= '9' {
+					selection = selection*10 + int(char-'0')
+					if selection*10 > len(selections) {
+						_ = player.Select(selection)
+						selection = 0
+					}
+					continue
+				}
+				if key == keyboard.KeyEnter {
+					_ = player.Select(selection)
+					selection = 0
+					continue
+				}
+			}
+			// Block number to go to, typed after 'j' and validated with Enter
+			if typed := blockInput.Load(); typed >= 0 {
+				if char >= '0' && char <= '9' {
+					setBlockInput(typed*10 + int64(char-'0'))
+					continue
+				}
+				if key == keyboard.KeyEnter {
+					_ = player.GoToBlock(int(typed))
+				}
+				setBlockInput(-1)
+				continue
+			}
+			if char == 'j' {
+				setBlockInput(0)
+			}
+			if char == 'n' {
+				player.NextBlock()
+			}
+			if char == 'b' {
+				player.PreviousBlock()
+			}
+			if char == 'h' || key == keyboard.KeyHome {
+				player.BlockStart()
+			}
+			if char == 'N' {
+				player.NextGroup()
+			}
+			if char == 'B' {
+				player.PreviousGroup()
+			}
 			if key == keyboard.KeySpace {
 				player.TogglePause()
 			}
@@ -183,7 +423,7 @@ func (c *Play) Exec(service *tape.Service, args []string) error {
 				}
 			}
 			if key == keyboard.KeyCtrlC {
-				sigs <- syscall.SIGTERM
+				quit()
 				break
 			}
 		}
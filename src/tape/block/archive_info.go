@@ -32,6 +32,26 @@ type Text struct {
 	text   string
 }
 
+// NewArchiveInfo creates a block from its texts
+func NewArchiveInfo(texts []Text) *ArchiveInfo {
+	return &ArchiveInfo{texts: texts}
+}
+
+// NewText creates a text of the given kind, as listed in TextIds
+func NewText(textId byte, text string) Text {
+	return Text{textId: textId, text: text}
+}
+
+// TextId returns the kind of the text, as listed in TextIds
+func (t Text) TextId() byte {
+	return t.textId
+}
+
+// Text returns the text
+func (t Text) Text() string {
+	return t.text
+}
+
 func (a *ArchiveInfo) Id() byte {
 	return 0x32
 }
@@ -98,3 +118,8 @@ func (a *ArchiveInfo) Pulses() []Pulse {
 func (a *ArchiveInfo) PauseDuration() int {
 	return 0
 }
+
+// Texts returns the texts of the block
+func (a *ArchiveInfo) Texts() []Text {
+	return a.texts
+}
@@ -2,13 +2,22 @@ package block
 
 import (
 	"fmt"
+	"io"
 	"os"
 )
 
+// ZXClockHz is the frequency of the clock of the pulses lengths, in T-states
+// per second
+const ZXClockHz = 3500000
+
+// NoId is the identifier of the blocks read from other formats than TZX,
+// which have no TZX block ID
+const NoId = 0x00
+
 // Block holds information and content of a TZX tape data block
 // @TODO: Implements others blocks types
 type Block interface {
-	// Id returns the identifier of this block
+	// Id returns the identifier of this block, NoId if it is not a TZX block
 	Id() byte
 
 	// Name returns the name of this block
@@ -31,12 +40,94 @@ type Block interface {
 	PauseDuration() int
 }
 
+// CompatibilityTail is implemented by the data blocks which can be followed by
+// a non-standard tail of pulses. The TZX specification doesn't define it, but
+// some loaders need it to properly detect the end of the data.
+type CompatibilityTail interface {
+	// TailPulses returns the pulses of the tail
+	TailPulses() []Pulse
+}
+
+// PilotTone is implemented by the data blocks starting with a pilot tone
+type PilotTone interface {
+	// PilotPulsesNb returns the number of pulses of the pilot tone, which are
+	// the first pulses of the block
+	PilotPulsesNb() int
+}
+
+// DataBits is implemented by the data blocks ending with data bits, each bit
+// being encoded by two pulses of the length given by its value
+type DataBits interface {
+	// DataBits returns the data, the number of bits used in the data, and the
+	// length of the pulses of the bits 0 and 1. The pulses of the bits are the
+	// last pulses of the block.
+	DataBits() (data []byte, bitsNb int, zeroPulseLength int, onePulseLength int)
+}
+
+// Silence is implemented by the blocks whose pulses may end with a silence.
+// As a silence is not a pulse, it is not finished like the pulses by the
+// pause following it.
+type Silence interface {
+	// EndsWithSilence tells if the last pulse of the block is a silence
+	EndsWithSilence() bool
+}
+
+// PzxBlock is implemented by the blocks read from PZX files
+type PzxBlock interface {
+	// PzxContent returns the tag and the content of the block in a PZX file
+	PzxContent() (tag string, content []byte)
+}
+
+// Pulse is a signal level held during some time. The level of a pulse is not
+// absolute: it is given by the edge at the beginning of the pulse applied to
+// the level of the previous one. The current level is carried from block to
+// block by the samples rendering. As stated by the TZX specification, the
+// first pulse after a pause, a signal level change or at the beginning of the
+// tape does not produce an edge.
 type Pulse struct {
 	// Length of the pulse in T state per second
 	Length int
 
-	// Low of High level (false = low, true = high)
-	Level bool
+	// Edge is the level change at the beginning of the pulse
+	Edge Edge
+}
+
+// Edge is the level change at the beginning of a pulse.
+// Values match the polarity bits of the TZX symbols definitions.
+type Edge byte
+
+const (
+	// EdgeToggle inverts the level of the previous pulse, if the previous pulse
+	// is finished by an edge
+	EdgeToggle Edge = iota
+
+	// EdgeNone keeps the level of the previous pulse
+	EdgeNone
+
+	// EdgeLow forces a low level
+	EdgeLow
+
+	// EdgeHigh forces a high level
+	EdgeHigh
+)
+
+// Level returns the level of the pulse from the level of the previous pulse,
+// and whether the previous pulse is finished by an edge. It also tells
+// whether the pulse is finished by an edge, which is not the case of a zero
+// length pulse forcing the level: it sets the level of the next pulse.
+func (p Pulse) Level(level bool, edgePending bool) (bool, bool) {
+	switch p.Edge {
+	case EdgeToggle:
+		if edgePending {
+			level = !level
+		}
+	case EdgeLow:
+		level = false
+	case EdgeHigh:
+		level = true
+	}
+	forced := p.Edge == EdgeLow || p.Edge == EdgeHigh
+	return level, p.Length > 0 || !forced
 }
 
 func NewBlock(id byte, tzxFile *os.File) (Block, error) {
@@ -55,12 +146,36 @@ func NewBlock(id byte, tzxFile *os.File) (Block, error) {
 		block = &PureDataBlock{}
 	case 0x15:
 		block = &DirectRecording{}
+	case 0x16:
+		block = &C64RomDataBlock{}
+	case 0x17:
+		block = &C64TurboDataBlock{}
+	case 0x18:
+		block = &CSWRecording{}
+	case 0x19:
+		block = &GeneralizedDataBlock{}
 	case 0x20:
 		block = &Pause{}
 	case 0x21:
 		block = &GroupStart{}
 	case 0x22:
 		block = &GroupEnd{}
+	case 0x23:
+		block = &Jump{}
+	case 0x24:
+		block = &LoopStart{}
+	case 0x25:
+		block = &LoopEnd{}
+	case 0x26:
+		block = &CallSequence{}
+	case 0x27:
+		block = &ReturnFromSequence{}
+	case 0x28:
+		block = &Select{}
+	case 0x2A:
+		block = &StopTape48K{}
+	case 0x2B:
+		block = &SetSignalLevel{}
 	case 0x30:
 		block = &TextDescription{}
 	case 0x31:
@@ -69,8 +184,18 @@ func NewBlock(id byte, tzxFile *os.File) (Block, error) {
 		block = &ArchiveInfo{}
 	case 0x33:
 		block = &HardwareType{}
+	case 0x34:
+		block = &EmulationInfo{}
+	case 0x35:
+		block = &CustomInfo{}
+	case 0x40:
+		block = &Snapshot{}
+	case 0x4B:
+		block = &KansasCityStandard{}
+	case 0x5A:
+		block = &Glue{}
 	default:
-		return nil, fmt.Errorf("unknown block id %x", id)
+		block = &UnknownBlock{id: id}
 	}
 
 	if err := block.Read(tzxFile); err != nil {
@@ -78,3 +203,21 @@ func NewBlock(id byte, tzxFile *os.File) (Block, error) {
 	}
 	return block, nil
 }
+
+// checkRemainingSize returns an error if less than length bytes are left to
+// be read in the file, so a corrupted block length does not lead to a huge
+// allocation
+func checkRemainingSize(tzxFile *os.File, length int64) error {
+	stat, err := tzxFile.Stat()
+	if err != nil {
+		return err
+	}
+	pos, err := tzxFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if length > stat.Size()-pos {
+		return fmt.Errorf("block length %d exceeds the %d bytes left in the file", length, stat.Size()-pos)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package block
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testFile returns a file opened for reading with the given content
+func testFile(t *testing.T, content []byte) *os.File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "block")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = f.Close()
+	})
+	return f
+}
@@ -0,0 +1,234 @@
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// c64RomHeader is the header of a C64 ROM Type Data Block
+type c64RomHeader struct {
+	BlockLength              uint32
+	PilotPulseLength         uint16
+	PilotWavesNb             uint16
+	SyncFirstPulseLength     uint16
+	SyncSecondPulseLength    uint16
+	ZeroBitFirstPulseLength  uint16
+	ZeroBitSecondPulseLength uint16
+	OneBitFirstPulseLength   uint16
+	OneBitSecondPulseLength  uint16
+	ChecksumBit              uint8
+	FinishByteFirstLength    uint16
+	FinishByteSecondLength   uint16
+	FinishDataFirstLength    uint16
+	FinishDataSecondLength   uint16
+	TrailingPulseLength      uint16
+	TrailingWavesNb          uint16
+	LastByteBitsUsed         uint8
+	GeneralPurpose           uint8
+	PauseAfterBlock          uint16
+	DataSize                 [3]byte
+}
+
+// c64TurboHeader is the header of a C64 Turbo Tape Data Block
+type c64TurboHeader struct {
+	BlockLength        uint32
+	ZeroBitPulseLength uint16
+	OneBitPulseLength  uint16
+	AdditionalBits     uint8
+	LeadInBytesNb      uint16
+	LeadInByte         uint8
+	LastByteBitsUsed   uint8
+	GeneralPurpose     uint8
+	TrailingBytesNb    uint16
+	TrailingByte       uint8
+	PauseAfterBlock    uint16
+	DataSize           [3]byte
+}
+
+// readBlock reads a block made of the given header followed by data
+func readBlock(t *testing.T, id byte, header interface{}, data []byte) Block {
+	t.Helper()
+	var content bytes.Buffer
+	if err := binary.Write(&content, binary.LittleEndian, header); err != nil {
+		t.Fatal(err)
+	}
+	content.Write(data)
+	b, err := NewBlock(id, testFile(t, content.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// dataSize encodes a 24 bits data size
+func dataSize(data []byte) [3]byte {
+	return [3]byte{byte(len(data)), byte(len(data) >> 8), byte(len(data) >> 16)}
+}
+
+// waves returns the pulses lengths of full waves made of two half-wave
+// pulses of the given lengths
+func waves(halfWaveLengths ...int) []int {
+	lengths := make([]int, 0)
+	for _, length := range halfWaveLengths {
+		lengths = append(lengths, length, length)
+	}
+	return lengths
+}
+
+// bitWaves returns the pulses lengths of the given bits, each made of
+// waves of the given lengths
+func bitWaves(zero []int, one []int, bits string) []int {
+	lengths := make([]int, 0)
+	for _, bit := range bits {
+		if bit == '1' {
+			lengths = append(lengths, waves(one...)...)
+		} else {
+			lengths = append(lengths, waves(zero...)...)
+		}
+	}
+	return lengths
+}
+
+// pulsesLengths returns the lengths of the given pulses
+func pulsesLengths(pulses []Pulse) []int {
+	lengths := make([]int, 0, len(pulses))
+	for _, pulse := range pulses {
+		lengths = append(lengths, pulse.Length)
+	}
+	return lengths
+}
+
+func concatLengths(parts ...[]int) []int {
+	lengths := make([]int, 0)
+	for _, part := range parts {
+		lengths = append(lengths, part...)
+	}
+	return lengths
+}
+
+func TestC64RomDataBlockPulses(t *testing.T) {
+	zero, one := []int{10, 20}, []int{30, 40}
+	tests := []struct {
+		name    string
+		header  c64RomHeader
+		data    []byte
+		lengths []int
+	}{
+		{
+			name: "LSb first with checksum and used bits in last byte",
+			header: c64RomHeader{
+				PilotPulseLength: 100, PilotWavesNb: 2,
+				SyncFirstPulseLength: 200, SyncSecondPulseLength: 300,
+				ZeroBitFirstPulseLength: 10, ZeroBitSecondPulseLength: 20,
+				OneBitFirstPulseLength: 30, OneBitSecondPulseLength: 40,
+				ChecksumBit:           1,
+				FinishByteFirstLength: 50, FinishByteSecondLength: 60,
+				FinishDataFirstLength: 70, FinishDataSecondLength: 80,
+				TrailingPulseLength: 90, TrailingWavesNb: 1,
+				LastByteBitsUsed: 3,
+			},
+			data: []byte{0x05, 0x03},
+			lengths: concatLengths(
+				waves(100, 100),
+				waves(200, 300),
+				bitWaves(zero, one, "10100000"+"1"),
+				waves(50, 60),
+				bitWaves(zero, one, "110"+"1"),
+				waves(70, 80),
+				waves(90),
+			),
+		},
+		{
+			name: "MSb first without checksum",
+			header: c64RomHeader{
+				PilotPulseLength: 100, PilotWavesNb: 1,
+				SyncFirstPulseLength:    200,
+				ZeroBitFirstPulseLength: 10, ZeroBitSecondPulseLength: 20,
+				OneBitFirstPulseLength: 30, OneBitSecondPulseLength: 40,
+				ChecksumBit:           C64NoChecksumBit,
+				FinishByteFirstLength: 50, FinishByteSecondLength: 60,
+				FinishDataFirstLength: 70,
+				GeneralPurpose:        0x01,
+			},
+			data: []byte{0x80, 0x01},
+			lengths: concatLengths(
+				waves(100),
+				waves(200),
+				bitWaves(zero, one, "10000000"),
+				waves(50, 60),
+				bitWaves(zero, one, "00000001"),
+				waves(70),
+			),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.header.DataSize = dataSize(test.data)
+			b := readBlock(t, 0x16, &test.header, test.data)
+			if lengths := pulsesLengths(b.Pulses()); !reflect.DeepEqual(lengths, test.lengths) {
+				t.Errorf("pulses lengths %v, expected %v", lengths, test.lengths)
+			}
+		})
+	}
+}
+
+func TestC64TurboDataBlockPulses(t *testing.T) {
+	zero, one := []int{10}, []int{20}
+	tests := []struct {
+		name    string
+		header  c64TurboHeader
+		data    []byte
+		lengths []int
+	}{
+		{
+			name: "LSb first with additional bits before each byte",
+			header: c64TurboHeader{
+				ZeroBitPulseLength: 10, OneBitPulseLength: 20,
+				AdditionalBits: 0x01 | 0x04 | 2<<3,
+				LeadInBytesNb:  1, LeadInByte: 0x01,
+				LastByteBitsUsed: 2,
+				TrailingBytesNb:  2, TrailingByte: 0x80,
+			},
+			data: []byte{0x02},
+			lengths: bitWaves(zero, one, ""+
+				"11"+"10000000"+ // Lead-in byte
+				"11"+"01"+ // Data byte
+				"11"+"00000001"+"11"+"00000001"), // Trailing bytes
+		},
+		{
+			name: "MSb first with an additional bit after each byte",
+			header: c64TurboHeader{
+				ZeroBitPulseLength: 10, OneBitPulseLength: 20,
+				AdditionalBits:   0x02,
+				LastByteBitsUsed: 2,
+				GeneralPurpose:   0x01,
+			},
+			data: []byte{0x40, 0xC0},
+			lengths: bitWaves(zero, one, ""+
+				"01000000"+"0"+
+				"11"+"0"),
+		},
+		{
+			name: "no additional bits",
+			header: c64TurboHeader{
+				ZeroBitPulseLength: 10, OneBitPulseLength: 20,
+				AdditionalBits: 0x04 | 3<<3,
+			},
+			data:    []byte{0x0F},
+			lengths: bitWaves(zero, one, "11110000"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.header.DataSize = dataSize(test.data)
+			b := readBlock(t, 0x17, &test.header, test.data)
+			if lengths := pulsesLengths(b.Pulses()); !reflect.DeepEqual(lengths, test.lengths) {
+				t.Errorf("pulses lengths %v, expected %v", lengths, test.lengths)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package block
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const C64NoChecksumBit = 0xFF
+
+// C64RomDataBlock - ID 16
+// Deprecated since TZX 1.20. A wave is made of two half-wave pulses of the same
+// length. A bit is made of two waves.
+type C64RomDataBlock struct {
+	pilotPulseLength         int
+	pilotWavesNb             int
+	syncFirstPulseLength     int
+	syncSecondPulseLength    int
+	zeroBitFirstPulseLength  int
+	zeroBitSecondPulseLength int
+	oneBitFirstPulseLength   int
+	oneBitSecondPulseLength  int
+	checksumBit              byte
+	finishByteFirstLength    int
+	finishByteSecondLength   int
+	finishDataFirstLength    int
+	finishDataSecondLength   int
+	trailingPulseLength      int
+	trailingWavesNb          int
+	lastByteBitsUsed         int
+	msbFirst                 bool
+	pauseAfterBlock          int
+	data                     []byte
+}
+
+func (c *C64RomDataBlock) Id() byte {
+	return 0x16
+}
+
+func (c *C64RomDataBlock) Name() string {
+	return "C64 ROM Type Data Block"
+}
+
+func (c *C64RomDataBlock) Read(tzxFile *os.File) error {
+	var header struct {
+		BlockLength              uint32
+		PilotPulseLength         uint16
+		PilotWavesNb             uint16
+		SyncFirstPulseLength     uint16
+		SyncSecondPulseLength    uint16
+		ZeroBitFirstPulseLength  uint16
+		ZeroBitSecondPulseLength uint16
+		OneBitFirstPulseLength   uint16
+		OneBitSecondPulseLength  uint16
+		ChecksumBit              uint8
+		FinishByteFirstLength    uint16
+		FinishByteSecondLength   uint16
+		FinishDataFirstLength    uint16
+		FinishDataSecondLength   uint16
+		TrailingPulseLength      uint16
+		TrailingWavesNb          uint16
+		LastByteBitsUsed         uint8
+		GeneralPurpose           uint8
+		PauseAfterBlock          uint16
+		DataSize                 [3]byte
+	}
+	if err := binary.Read(tzxFile, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	c.pilotPulseLength = int(header.PilotPulseLength)
+	c.pilotWavesNb = int(header.PilotWavesNb)
+	c.syncFirstPulseLength = int(header.SyncFirstPulseLength)
+	c.syncSecondPulseLength = int(header.SyncSecondPulseLength)
+	c.zeroBitFirstPulseLength = int(header.ZeroBitFirstPulseLength)
+	c.zeroBitSecondPulseLength = int(header.ZeroBitSecondPulseLength)
+	c.oneBitFirstPulseLength = int(header.OneBitFirstPulseLength)
+	c.oneBitSecondPulseLength = int(header.OneBitSecondPulseLength)
+	c.checksumBit = header.ChecksumBit
+	c.finishByteFirstLength = int(header.FinishByteFirstLength)
+	c.finishByteSecondLength = int(header.FinishByteSecondLength)
+	c.finishDataFirstLength = int(header.FinishDataFirstLength)
+	c.finishDataSecondLength = int(header.FinishDataSecondLength)
+	c.trailingPulseLength = int(header.TrailingPulseLength)
+	c.trailingWavesNb = int(header.TrailingWavesNb)
+	c.lastByteBitsUsed = int(header.LastByteBitsUsed)
+	c.msbFirst = header.GeneralPurpose&0x01 > 0
+	c.pauseAfterBlock = int(header.PauseAfterBlock)
+
+	data := make([]byte, binary.LittleEndian.Uint32(append(header.DataSize[:], 0)))
+	if _, err := tzxFile.Read(data); err != nil {
+		return err
+	}
+	c.data = data
+
+	return nil
+}
+
+func (c *C64RomDataBlock) Info() [][]string {
+	checksum := "None"
+	if c.checksumBit != C64NoChecksumBit {
+		checksum = fmt.Sprintf("Starts with %d", c.checksumBit)
+	}
+	return [][]string{
+		{"PILOT pulse length", strconv.Itoa(c.pilotPulseLength)},
+		{"PILOT waves number", strconv.Itoa(c.pilotWavesNb)},
+		{"SYNC first wave pulse length", strconv.Itoa(c.syncFirstPulseLength)},
+		{"SYNC second wave pulse length", strconv.Itoa(c.syncSecondPulseLength)},
+		{"ZERO bit first wave pulse length", strconv.Itoa(c.zeroBitFirstPulseLength)},
+		{"ZERO bit second wave pulse length", strconv.Itoa(c.zeroBitSecondPulseLength)},
+		{"ONE bit first wave pulse length", strconv.Itoa(c.oneBitFirstPulseLength)},
+		{"ONE bit second wave pulse length", strconv.Itoa(c.oneBitSecondPulseLength)},
+		{"XOR checksum bit", checksum},
+		{"FINISH BYTE first wave pulse length", strconv.Itoa(c.finishByteFirstLength)},
+		{"FINISH BYTE second wave pulse length", strconv.Itoa(c.finishByteSecondLength)},
+		{"FINISH DATA first wave pulse length", strconv.Itoa(c.finishDataFirstLength)},
+		{"FINISH DATA second wave pulse length", strconv.Itoa(c.finishDataSecondLength)},
+		{"TRAILING pulse length", strconv.Itoa(c.trailingPulseLength)},
+		{"TRAILING waves number", strconv.Itoa(c.trailingWavesNb)},
+		{"Used bits in last byte", strconv.Itoa(c.lastByteBitsUsed)},
+		{"Bit order", bitOrderName(c.msbFirst)},
+		{"Pause after block", fmt.Sprintf("%d ms", c.pauseAfterBlock)},
+		{"Data length", strconv.Itoa(len(c.data))},
+	}
+}
+
+func (c *C64RomDataBlock) Pulses() []Pulse {
+	pulses := make([]Pulse, 0)
+
+	// Generate pilot tone
+	for i := 0; i < c.pilotWavesNb; i++ {
+		pulses = appendWave(pulses, c.pilotPulseLength)
+	}
+
+	// Generate sync waves
+	pulses = appendWave(pulses, c.syncFirstPulseLength)
+	pulses = appendWave(pulses, c.syncSecondPulseLength)
+
+	// Generate data waves
+	for i, dataByte := range c.data {
+		bitsNb := 8
+		if i == len(c.data)-1 && c.lastByteBitsUsed > 0 {
+			bitsNb = c.lastByteBitsUsed
+		}
+
+		checksum := c.checksumBit
+		for _, bit := range byteBits(dataByte, bitsNb, c.msbFirst) {
+			pulses = c.appendBit(pulses, bit)
+			if bit {
+				checksum ^= 1
+			}
+		}
+		if c.checksumBit != C64NoChecksumBit {
+			pulses = c.appendBit(pulses, checksum == 1)
+		}
+
+		if i < len(c.data)-1 {
+			pulses = appendWave(pulses, c.finishByteFirstLength)
+			pulses = appendWave(pulses, c.finishByteSecondLength)
+		} else {
+			pulses = appendWave(pulses, c.finishDataFirstLength)
+			pulses = appendWave(pulses, c.finishDataSecondLength)
+		}
+	}
+
+	// Generate trailing tone
+	for i := 0; i < c.trailingWavesNb; i++ {
+		pulses = appendWave(pulses, c.trailingPulseLength)
+	}
+
+	return pulses
+}
+
+func (c *C64RomDataBlock) PauseDuration() int {
+	return c.pauseAfterBlock
+}
+
+// appendBit appends the two waves of a bit
+func (c *C64RomDataBlock) appendBit(pulses []Pulse, bit bool) []Pulse {
+	if bit {
+		pulses = appendWave(pulses, c.oneBitFirstPulseLength)
+		return appendWave(pulses, c.oneBitSecondPulseLength)
+	}
+	pulses = appendWave(pulses, c.zeroBitFirstPulseLength)
+	return appendWave(pulses, c.zeroBitSecondPulseLength)
+}
+
+// appendWave appends a full wave made of two half-wave pulses of the given
+// length. Nothing is appended for a zero length.
+func appendWave(pulses []Pulse, halfWaveLength int) []Pulse {
+	if halfWaveLength == 0 {
+		return pulses
+	}
+	return append(pulses, Pulse{Length: halfWaveLength}, Pulse{Length: halfWaveLength})
+}
+
+// byteBits returns the first bitsNb bits of a byte, in the given bit order
+func byteBits(b byte, bitsNb int, msbFirst bool) []bool {
+	bits := make([]bool, 0, bitsNb)
+	for i := 0; i < bitsNb; i++ {
+		if msbFirst {
+			bits = append(bits, b&(0x80>>i) > 0)
+		} else {
+			bits = append(bits, b&(0x01<<i) > 0)
+		}
+	}
+	return bits
+}
+
+// bitOrderName describes a bit order
+func bitOrderName(msbFirst bool) string {
+	if msbFirst {
+		return "MSb first"
+	}
+	return "LSb first"
+}
@@ -0,0 +1,171 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// C64 TAP video standards
+const C64VideoPal = 0x00
+const C64VideoNtsc = 0x01
+const C64VideoOldNtsc = 0x02
+const C64VideoPalN = 0x03
+
+// C64 TAP platforms
+const C64PlatformC64 = 0x00
+const C64PlatformVic20 = 0x01
+const C64PlatformC16 = 0x02
+
+// C64TapV0OverflowCycles is the length in cycles given to the overflow pulses
+// of the version 0 files, whose length is not stored
+const C64TapV0OverflowCycles = 256 * 8
+
+var C64Platforms map[byte]string
+var C64VideoStandards map[byte]string
+
+// c64Clocks are the CPU clocks in Hz of the platforms, in PAL and NTSC
+var c64Clocks map[byte][2]int
+
+func init() {
+	C64Platforms = map[byte]string{
+		C64PlatformC64:   "C64",
+		C64PlatformVic20: "VIC-20",
+		C64PlatformC16:   "C16",
+	}
+	C64VideoStandards = map[byte]string{
+		C64VideoPal:     "PAL",
+		C64VideoNtsc:    "NTSC",
+		C64VideoOldNtsc: "Old NTSC",
+		C64VideoPalN:    "PAL-N",
+	}
+	c64Clocks = map[byte][2]int{
+		C64PlatformC64:   {985248, 1022727},
+		C64PlatformVic20: {1108405, 1022727},
+		C64PlatformC16:   {886724, 894886},
+	}
+}
+
+// C64TapeRecording - Commodore TAP file content (no TZX ID)
+type C64TapeRecording struct {
+	version       byte
+	platform      byte
+	videoStandard byte
+	cycles        []int
+}
+
+// NewC64TapeRecording creates a block from the data of a Commodore TAP file
+// of the given version, platform and video standard. Pulses are full waves
+// in versions 0 and 1, half waves in version 2.
+func NewC64TapeRecording(version byte, platform byte, videoStandard byte, data []byte) (*C64TapeRecording, error) {
+	if version > 2 {
+		return nil, fmt.Errorf("c64 tape recording: unsupported version %d", version)
+	}
+
+	c := &C64TapeRecording{
+		version:       version,
+		platform:      platform,
+		videoStandard: videoStandard,
+	}
+	for i := 0; i < len(data); i++ {
+		if data[i] != 0 {
+			c.cycles = append(c.cycles, int(data[i])*8)
+			continue
+		}
+		if version == 0 {
+			c.cycles = append(c.cycles, C64TapV0OverflowCycles)
+			continue
+		}
+		if i+3 >= len(data) {
+			return nil, errors.New("c64 tape recording: truncated long pulse")
+		}
+		c.cycles = append(c.cycles, int(data[i+1])|int(data[i+2])<<8|int(data[i+3])<<16)
+		i += 3
+	}
+
+	return c, nil
+}
+
+func (c *C64TapeRecording) Id() byte {
+	return NoId
+}
+
+func (c *C64TapeRecording) Name() string {
+	return "C64 Tape Recording"
+}
+
+func (c *C64TapeRecording) Read(tzxFile *os.File) error {
+	return errors.New("c64 tape recording: not a TZX block")
+}
+
+func (c *C64TapeRecording) Info() [][]string {
+	waves := "Full waves"
+	if c.version == 2 {
+		waves = "Half waves"
+	}
+	return [][]string{
+		{"Platform", C64Platforms[c.platform]},
+		{"Video standard", C64VideoStandards[c.videoStandard]},
+		{"Clock", fmt.Sprintf("%d Hz", c.clock())},
+		{"Pulses type", waves},
+		{"Pulses number", strconv.Itoa(len(c.cycles))},
+	}
+}
+
+// Pulses returns the pulses of the recording, converted from the clock of the
+// platform to T-states. Full waves are made of a low then a high pulse.
+func (c *C64TapeRecording) Pulses() []Pulse {
+	pulses := make([]Pulse, 0, len(c.cycles)*2)
+	clock := int64(c.clock())
+
+	// The positions are computed from the elapsed cycles, so the rounding
+	// errors of the pulses lengths don't add up
+	var cycles int64
+	var pos int
+	appendPulse := func(length int) {
+		cycles += int64(length)
+		end := int((cycles*ZXClockHz + clock/2) / clock)
+		edge := EdgeToggle
+		if len(pulses) == 0 {
+			edge = EdgeLow
+		}
+		pulses = append(pulses, Pulse{Length: end - pos, Edge: edge})
+		pos = end
+	}
+
+	for _, length := range c.cycles {
+		if c.version == 2 {
+			appendPulse(length)
+			continue
+		}
+		appendPulse(length / 2)
+		appendPulse(length - length/2)
+	}
+
+	return pulses
+}
+
+func (c *C64TapeRecording) PauseDuration() int {
+	return 0
+}
+
+// WithVideoStandard returns a copy of the block played with the given video
+// standard, which gives the clock of the pulses lengths
+func (c *C64TapeRecording) WithVideoStandard(videoStandard byte) *C64TapeRecording {
+	recording := *c
+	recording.videoStandard = videoStandard
+	return &recording
+}
+
+// clock returns the CPU clock in Hz of the platform and video standard
+func (c *C64TapeRecording) clock() int {
+	clocks, ok := c64Clocks[c.platform]
+	if !ok {
+		clocks = c64Clocks[C64PlatformC64]
+	}
+	if c.videoStandard == C64VideoNtsc || c.videoStandard == C64VideoOldNtsc {
+		return clocks[1]
+	}
+	return clocks[0]
+}
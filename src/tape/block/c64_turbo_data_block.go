@@ -0,0 +1,168 @@
+package block
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+var C64AdditionalBitsPositions map[byte]string
+
+func init() {
+	C64AdditionalBitsPositions = map[byte]string{
+		0x00: "None",
+		0x01: "Before each byte",
+		0x02: "After each byte",
+	}
+}
+
+// C64TurboDataBlock - ID 17
+// Deprecated since TZX 1.20. A bit is made of one wave of two half-wave pulses
+// of the same length.
+type C64TurboDataBlock struct {
+	zeroBitPulseLength int
+	oneBitPulseLength  int
+	additionalBits     byte
+	leadInBytesNb      int
+	leadInByte         byte
+	lastByteBitsUsed   int
+	msbFirst           bool
+	trailingBytesNb    int
+	trailingByte       byte
+	pauseAfterBlock    int
+	data               []byte
+}
+
+func (c *C64TurboDataBlock) Id() byte {
+	return 0x17
+}
+
+func (c *C64TurboDataBlock) Name() string {
+	return "C64 Turbo Tape Data Block"
+}
+
+func (c *C64TurboDataBlock) Read(tzxFile *os.File) error {
+	var header struct {
+		BlockLength        uint32
+		ZeroBitPulseLength uint16
+		OneBitPulseLength  uint16
+		AdditionalBits     uint8
+		LeadInBytesNb      uint16
+		LeadInByte         uint8
+		LastByteBitsUsed   uint8
+		GeneralPurpose     uint8
+		TrailingBytesNb    uint16
+		TrailingByte       uint8
+		PauseAfterBlock    uint16
+		DataSize           [3]byte
+	}
+	if err := binary.Read(tzxFile, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	c.zeroBitPulseLength = int(header.ZeroBitPulseLength)
+	c.oneBitPulseLength = int(header.OneBitPulseLength)
+	c.additionalBits = header.AdditionalBits
+	c.leadInBytesNb = int(header.LeadInBytesNb)
+	c.leadInByte = header.LeadInByte
+	c.lastByteBitsUsed = int(header.LastByteBitsUsed)
+	c.msbFirst = header.GeneralPurpose&0x01 > 0
+	c.trailingBytesNb = int(header.TrailingBytesNb)
+	c.trailingByte = header.TrailingByte
+	c.pauseAfterBlock = int(header.PauseAfterBlock)
+
+	data := make([]byte, binary.LittleEndian.Uint32(append(header.DataSize[:], 0)))
+	if _, err := tzxFile.Read(data); err != nil {
+		return err
+	}
+	c.data = data
+
+	return nil
+}
+
+func (c *C64TurboDataBlock) Info() [][]string {
+	return [][]string{
+		{"ZERO bit pulse length", strconv.Itoa(c.zeroBitPulseLength)},
+		{"ONE bit pulse length", strconv.Itoa(c.oneBitPulseLength)},
+		{"Additional bits", C64AdditionalBitsPositions[c.additionalBits&0x03]},
+		{"Additional bits value", strconv.Itoa(int(c.additionalBits>>2) & 0x01)},
+		{"Additional bits number", strconv.Itoa(c.additionalBitsNb())},
+		{"Lead-in bytes number", strconv.Itoa(c.leadInBytesNb)},
+		{"Lead-in byte", fmt.Sprintf("%x", c.leadInByte)},
+		{"Used bits in last byte", strconv.Itoa(c.lastByteBitsUsed)},
+		{"Bit order", bitOrderName(c.msbFirst)},
+		{"Trailing bytes number", strconv.Itoa(c.trailingBytesNb)},
+		{"Trailing byte", fmt.Sprintf("%x", c.trailingByte)},
+		{"Pause after block", fmt.Sprintf("%d ms", c.pauseAfterBlock)},
+		{"Data length", strconv.Itoa(len(c.data))},
+	}
+}
+
+func (c *C64TurboDataBlock) Pulses() []Pulse {
+	pulses := make([]Pulse, 0)
+
+	// Generate lead-in bytes
+	for i := 0; i < c.leadInBytesNb; i++ {
+		pulses = c.appendByte(pulses, c.leadInByte, 8)
+	}
+
+	// Generate data bytes
+	for i, dataByte := range c.data {
+		bitsNb := 8
+		if i == len(c.data)-1 && c.lastByteBitsUsed > 0 {
+			bitsNb = c.lastByteBitsUsed
+		}
+		pulses = c.appendByte(pulses, dataByte, bitsNb)
+	}
+
+	// Generate trailing bytes
+	for i := 0; i < c.trailingBytesNb; i++ {
+		pulses = c.appendByte(pulses, c.trailingByte, 8)
+	}
+
+	return pulses
+}
+
+func (c *C64TurboDataBlock) PauseDuration() int {
+	return c.pauseAfterBlock
+}
+
+// appendByte appends the waves of the first bitsNb bits of a byte, surrounded
+// by the additional bits
+func (c *C64TurboDataBlock) appendByte(pulses []Pulse, b byte, bitsNb int) []Pulse {
+	additionalBitValue := c.additionalBits&0x04 > 0
+	if c.additionalBits&0x03 == 0x01 {
+		for i := 0; i < c.additionalBitsNb(); i++ {
+			pulses = c.appendBit(pulses, additionalBitValue)
+		}
+	}
+	for _, bit := range byteBits(b, bitsNb, c.msbFirst) {
+		pulses = c.appendBit(pulses, bit)
+	}
+	if c.additionalBits&0x03 == 0x02 {
+		for i := 0; i < c.additionalBitsNb(); i++ {
+			pulses = c.appendBit(pulses, additionalBitValue)
+		}
+	}
+	return pulses
+}
+
+// appendBit appends the wave of a bit
+func (c *C64TurboDataBlock) appendBit(pulses []Pulse, bit bool) []Pulse {
+	if bit {
+		return appendWave(pulses, c.oneBitPulseLength)
+	}
+	return appendWave(pulses, c.zeroBitPulseLength)
+}
+
+// additionalBitsNb returns the number of additional bits before or after each byte
+func (c *C64TurboDataBlock) additionalBitsNb() int {
+	if c.additionalBits&0x03 == 0 {
+		return 0
+	}
+	nb := int(c.additionalBits>>3) & 0x1F
+	if nb == 0 {
+		return 1
+	}
+	return nb
+}
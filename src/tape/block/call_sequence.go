@@ -0,0 +1,62 @@
+package block
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CallSequence - ID 26
+type CallSequence struct {
+	offsets []int
+}
+
+func (c *CallSequence) Id() byte {
+	return 0x26
+}
+
+func (c *CallSequence) Name() string {
+	return "Call sequence"
+}
+
+func (c *CallSequence) Read(tzxFile *os.File) error {
+	var callsNb uint16
+	if err := binary.Read(tzxFile, binary.LittleEndian, &callsNb); err != nil {
+		return err
+	}
+
+	offsets := make([]int16, callsNb)
+	if err := binary.Read(tzxFile, binary.LittleEndian, offsets); err != nil {
+		return err
+	}
+	for _, offset := range offsets {
+		c.offsets = append(c.offsets, int(offset))
+	}
+
+	return nil
+}
+
+func (c *CallSequence) Info() [][]string {
+	offsets := make([]string, 0)
+	for _, offset := range c.offsets {
+		offsets = append(offsets, strconv.Itoa(offset))
+	}
+	return [][]string{
+		{"Number of calls", strconv.Itoa(len(c.offsets))},
+		{"Relative call values", strings.Join(offsets, ", ")},
+	}
+}
+
+func (c *CallSequence) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (c *CallSequence) PauseDuration() int {
+	return 0
+}
+
+// Offsets returns the offsets, relative to this block, of the blocks to call
+func (c *CallSequence) Offsets() []int {
+	return c.offsets
+}
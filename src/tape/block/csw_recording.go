@@ -0,0 +1,192 @@
+package block
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+const CswCompressionRle = 0x01
+const CswCompressionZRle = 0x02
+
+var CswCompressionTypes map[byte]string
+
+func init() {
+	CswCompressionTypes = map[byte]string{
+		CswCompressionRle:  "RLE",
+		CswCompressionZRle: "Z-RLE",
+	}
+}
+
+// CSWRecording - ID 18
+type CSWRecording struct {
+	pauseAfterBlock int
+	samplingRate    int
+	compressionType byte
+	pulsesNb        int
+	samplesLengths  []int
+	firstEdge       Edge
+}
+
+// NewCSWRecording creates a block from CSW compressed data. The level of the
+// first pulse is given by initialLevel.
+func NewCSWRecording(samplingRate int, compressionType byte, data []byte, initialLevel bool) (*CSWRecording, error) {
+	if samplingRate == 0 {
+		return nil, errors.New("csw recording: invalid sampling rate")
+	}
+
+	samplesLengths, err := decodeCsw(data, compressionType)
+	if err != nil {
+		return nil, fmt.Errorf("csw recording: %s", err.Error())
+	}
+
+	firstEdge := EdgeLow
+	if initialLevel {
+		firstEdge = EdgeHigh
+	}
+
+	return &CSWRecording{
+		samplingRate:    samplingRate,
+		compressionType: compressionType,
+		pulsesNb:        len(samplesLengths),
+		samplesLengths:  samplesLengths,
+		firstEdge:       firstEdge,
+	}, nil
+}
+
+func (c *CSWRecording) Id() byte {
+	return 0x18
+}
+
+func (c *CSWRecording) Name() string {
+	return "CSW Recording"
+}
+
+func (c *CSWRecording) Read(tzxFile *os.File) error {
+	var header struct {
+		BlockLength     uint32
+		PauseAfterBlock uint16
+		SamplingRate    [3]byte
+		CompressionType uint8
+		PulsesNb        uint32
+	}
+	if err := binary.Read(tzxFile, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	c.pauseAfterBlock = int(header.PauseAfterBlock)
+	c.samplingRate = int(binary.LittleEndian.Uint32(append(header.SamplingRate[:], 0)))
+	c.compressionType = header.CompressionType
+	c.pulsesNb = int(header.PulsesNb)
+
+	if header.BlockLength < 10 {
+		return errors.New("csw recording: invalid block length")
+	}
+	if c.samplingRate == 0 {
+		return errors.New("csw recording: invalid sampling rate")
+	}
+
+	if err := checkRemainingSize(tzxFile, int64(header.BlockLength-10)); err != nil {
+		return err
+	}
+	data := make([]byte, header.BlockLength-10)
+	if _, err := io.ReadFull(tzxFile, data); err != nil {
+		return err
+	}
+
+	samplesLengths, err := decodeCsw(data, c.compressionType)
+	if err != nil {
+		return fmt.Errorf("csw recording: %s", err.Error())
+	}
+	if len(samplesLengths) != c.pulsesNb {
+		return fmt.Errorf("csw recording: %d pulses decoded, %d stored", len(samplesLengths), c.pulsesNb)
+	}
+	c.samplesLengths = samplesLengths
+
+	return nil
+}
+
+func (c *CSWRecording) Info() [][]string {
+	return [][]string{
+		{"Pause after block", fmt.Sprintf("%d ms", c.pauseAfterBlock)},
+		{"Sampling rate", fmt.Sprintf("%d Hz", c.samplingRate)},
+		{"Compression type", CswCompressionTypes[c.compressionType]},
+		{"Number of stored pulses", strconv.Itoa(c.pulsesNb)},
+	}
+}
+
+func (c *CSWRecording) Pulses() []Pulse {
+	pulses := make([]Pulse, 0, len(c.samplesLengths))
+
+	// Lengths are converted from the total elapsed samples to prevent
+	// rounding errors to accumulate
+	elapsedSamples := 0
+	elapsedTStates := 0
+	for _, samples := range c.samplesLengths {
+		elapsedSamples += samples
+		tStates := int(int64(elapsedSamples) * ZXClockHz / int64(c.samplingRate))
+		pulse := Pulse{Length: tStates - elapsedTStates}
+		if len(pulses) == 0 {
+			pulse.Edge = c.firstEdge
+		}
+		pulses = append(pulses, pulse)
+		elapsedTStates = tStates
+	}
+
+	return pulses
+}
+
+func (c *CSWRecording) PauseDuration() int {
+	return c.pauseAfterBlock
+}
+
+// PulsesNb returns the number of pulses of the recording
+func (c *CSWRecording) PulsesNb() int {
+	return c.pulsesNb
+}
+
+// decodeCsw decodes CSW RLE or Z-RLE compressed data into pulses lengths
+// expressed in samples
+func decodeCsw(data []byte, compressionType byte) ([]int, error) {
+	var rle io.Reader
+	switch compressionType {
+	case CswCompressionRle:
+		rle = bytes.NewReader(data)
+	case CswCompressionZRle:
+		z, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = z.Close()
+		}()
+		rle = z
+	default:
+		return nil, fmt.Errorf("unknown compression type %x", compressionType)
+	}
+
+	rleBytes, err := io.ReadAll(rle)
+	if err != nil {
+		return nil, err
+	}
+
+	lengths := make([]int, 0, len(rleBytes))
+	for i := 0; i < len(rleBytes); i++ {
+		if rleBytes[i] > 0 {
+			lengths = append(lengths, int(rleBytes[i]))
+			continue
+		}
+		// A zero byte is followed by the length stored in a 32 bits value
+		if i+4 >= len(rleBytes) {
+			return nil, errors.New("truncated RLE data")
+		}
+		lengths = append(lengths, int(binary.LittleEndian.Uint32(rleBytes[i+1:i+5])))
+		i += 4
+	}
+
+	return lengths, nil
+}
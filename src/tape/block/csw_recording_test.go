@@ -0,0 +1,66 @@
+package block
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// cswRecording returns the content of a CSW Recording block following its ID
+func cswRecording(samplingRate uint32, compression byte, pulsesNb uint32, data []byte) []byte {
+	content := binary.LittleEndian.AppendUint32(nil, uint32(len(data)+10))
+	content = binary.LittleEndian.AppendUint16(content, 0)
+	content = append(content, byte(samplingRate), byte(samplingRate>>8), byte(samplingRate>>16), compression)
+	content = binary.LittleEndian.AppendUint32(content, pulsesNb)
+	return append(content, data...)
+}
+
+func TestCSWRecordingRead(t *testing.T) {
+	rle := []byte{10, 20, 0, 0x2c, 0x01, 0, 0}
+	var zrle bytes.Buffer
+	z := zlib.NewWriter(&zrle)
+	_, _ = z.Write(rle)
+	_ = z.Close()
+
+	hugeLength := cswRecording(35000, CswCompressionRle, 1, []byte{1})
+	binary.LittleEndian.PutUint32(hugeLength, 0xffffffff)
+
+	pulses := []Pulse{{1000, EdgeToggle}, {2000, EdgeToggle}, {30000, EdgeToggle}}
+	tests := []struct {
+		name    string
+		content []byte
+		pulses  []Pulse
+		err     string
+	}{
+		{"rle", cswRecording(35000, CswCompressionRle, 3, rle), pulses, ""},
+		{"z-rle", cswRecording(35000, CswCompressionZRle, 3, zrle.Bytes()), pulses, ""},
+		{"wrong pulses number", cswRecording(35000, CswCompressionRle, 4, rle), nil, "3 pulses decoded, 4 stored"},
+		{"truncated rle", cswRecording(35000, CswCompressionRle, 3, rle[:5]), nil, "truncated RLE data"},
+		{"truncated block", cswRecording(35000, CswCompressionRle, 3, rle)[:16], nil, "exceeds the 2 bytes left in the file"},
+		{"huge block length", hugeLength, nil, "exceeds the 1 bytes left in the file"},
+		{"unknown compression", cswRecording(35000, 3, 3, rle), nil, "unknown compression type 3"},
+		{"zero sampling rate", cswRecording(0, CswCompressionRle, 3, rle), nil, "invalid sampling rate"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &CSWRecording{}
+			err := c.Read(testFile(t, test.content))
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pulses := c.Pulses(); !reflect.DeepEqual(pulses, test.pulses) {
+				t.Errorf("pulses %v, expected %v", pulses, test.pulses)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package block
+
+import (
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/text/encoding/charmap"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const CustomInfoPokes = "POKEs"
+const CustomInfoInstructions = "Instructions"
+const CustomInfoPicture = "Picture"
+const CustomInfoSpectrumScreen = "Spectrum Screen"
+
+// SpectrumScreenSize is the size of a ZX Spectrum screen memory dump
+const SpectrumScreenSize = 6912
+
+var PictureFormats map[byte]string
+var SpectrumColours map[byte]string
+
+func init() {
+	PictureFormats = map[byte]string{
+		0x00: "gif",
+		0x01: "jpg",
+	}
+	SpectrumColours = map[byte]string{
+		0x00: "black",
+		0x01: "blue",
+		0x02: "red",
+		0x03: "magenta",
+		0x04: "green",
+		0x05: "cyan",
+		0x06: "yellow",
+		0x07: "white",
+	}
+}
+
+// CustomInfo - ID 35
+type CustomInfo struct {
+	identification string
+	info           []byte
+}
+
+func (c *CustomInfo) Id() byte {
+	return 0x35
+}
+
+func (c *CustomInfo) Name() string {
+	return "Custom Info"
+}
+
+func (c *CustomInfo) Read(tzxFile *os.File) error {
+	var header struct {
+		Identification [16]byte
+		InfoLength     uint32
+	}
+	if err := binary.Read(tzxFile, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	c.identification = strings.TrimRight(string(header.Identification[:]), " \x00")
+
+	if err := checkRemainingSize(tzxFile, int64(header.InfoLength)); err != nil {
+		return fmt.Errorf("custom info: %s", err.Error())
+	}
+	info := make([]byte, header.InfoLength)
+	if _, err := io.ReadFull(tzxFile, info); err != nil {
+		return err
+	}
+	c.info = info
+
+	return nil
+}
+
+func (c *CustomInfo) Info() [][]string {
+	info := [][]string{
+		{"Identification", c.identification},
+		{"Info length", strconv.Itoa(len(c.info))},
+	}
+
+	var details [][]string
+	var err error
+	switch c.identification {
+	case CustomInfoPokes:
+		details, err = c.pokesInfo()
+	case CustomInfoInstructions:
+		for i, line := range strings.Split(decodeText(c.info), "\r") {
+			key := ""
+			if i == 0 {
+				key = "Instructions"
+			}
+			details = append(details, []string{key, line})
+		}
+	case CustomInfoPicture:
+		var description string
+		description, _, err = c.describedPayload()
+		details = [][]string{{"Description", description}}
+	case CustomInfoSpectrumScreen:
+		var description string
+		var border byte
+		description, _, err = c.describedPayload()
+		if err == nil {
+			border, _, err = c.spectrumScreen()
+		}
+		details = [][]string{
+			{"Description", description},
+			{"Border colour", fmt.Sprintf("%d (%s)", border, SpectrumColours[border])},
+		}
+	}
+
+	if err != nil {
+		details = [][]string{{"Error", err.Error()}}
+	}
+	return append(info, details...)
+}
+
+func (c *CustomInfo) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (c *CustomInfo) PauseDuration() int {
+	return 0
+}
+
+// Identification returns the identification string of the info
+func (c *CustomInfo) Identification() string {
+	return c.identification
+}
+
+// Payload returns the content of the info in a form suitable to be stored
+// as a file, with the matching file extension
+func (c *CustomInfo) Payload() (data []byte, extension string) {
+	switch c.identification {
+	case CustomInfoInstructions:
+		return []byte(strings.ReplaceAll(decodeText(c.info), "\r", "\n")), "txt"
+	case CustomInfoPicture:
+		if _, picture, err := c.describedPayload(); err == nil && len(picture) > 0 {
+			if format, ok := PictureFormats[c.info[0]]; ok {
+				return picture, format
+			}
+		}
+	case CustomInfoSpectrumScreen:
+		if _, screen, err := c.spectrumScreen(); err == nil {
+			return screen, "scr"
+		}
+	}
+	return c.info, "bin"
+}
+
+// describedPayload splits the Picture and Spectrum Screen infos into their
+// description and their binary data
+func (c *CustomInfo) describedPayload() (string, []byte, error) {
+	pos := 0
+	if c.identification == CustomInfoPicture {
+		pos++ // Skip picture format
+	}
+	if pos >= len(c.info) {
+		return "", nil, fmt.Errorf("truncated %s info", c.identification)
+	}
+	descriptionEnd := pos + 1 + int(c.info[pos])
+	if descriptionEnd > len(c.info) {
+		return "", nil, fmt.Errorf("truncated %s info", c.identification)
+	}
+	return decodeText(c.info[pos+1 : descriptionEnd]), c.info[descriptionEnd:], nil
+}
+
+// spectrumScreen splits the binary data of the Spectrum Screen info into its
+// border colour and its screen memory dump
+func (c *CustomInfo) spectrumScreen() (byte, []byte, error) {
+	_, data, err := c.describedPayload()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) != 1+SpectrumScreenSize {
+		return 0, nil, fmt.Errorf("%s info of %d bytes instead of %d", c.identification, len(data), 1+SpectrumScreenSize)
+	}
+	return data[0], data[1:], nil
+}
+
+// pokesInfo decodes the POKEs info
+func (c *CustomInfo) pokesInfo() ([][]string, error) {
+	info := make([][]string, 0)
+	pos := 0
+	truncated := fmt.Errorf("truncated %s info", CustomInfoPokes)
+
+	readText := func() (string, error) {
+		if pos >= len(c.info) || pos+1+int(c.info[pos]) > len(c.info) {
+			return "", truncated
+		}
+		text := decodeText(c.info[pos+1 : pos+1+int(c.info[pos])])
+		pos += 1 + int(c.info[pos])
+		return text, nil
+	}
+
+	description, err := readText()
+	if err != nil {
+		return nil, err
+	}
+	info = append(info, []string{"Description", description})
+
+	if pos >= len(c.info) {
+		return nil, truncated
+	}
+	trainersNb := int(c.info[pos])
+	pos++
+
+	for i := 1; i <= trainersNb; i++ {
+		trainer, err := readText()
+		if err != nil {
+			return nil, err
+		}
+		info = append(info, []string{fmt.Sprintf("Trainer %d", i), trainer})
+
+		if pos >= len(c.info) {
+			return nil, truncated
+		}
+		pokesNb := int(c.info[pos])
+		pos++
+
+		for j := 1; j <= pokesNb; j++ {
+			if pos+5 > len(c.info) {
+				return nil, truncated
+			}
+			info = append(info, []string{fmt.Sprintf("Trainer %d POKE %d", i, j), pokeDescription(c.info[pos : pos+5])})
+			pos += 5
+		}
+	}
+
+	return info, nil
+}
+
+// pokeDescription describes a POKE entry of a POKEs info
+func pokeDescription(poke []byte) string {
+	flags := poke[0]
+	description := fmt.Sprintf("address %d", binary.LittleEndian.Uint16(poke[1:3]))
+	if flags&0x08 == 0 {
+		description = fmt.Sprintf("RAM page %d, %s", flags&0x07, description)
+	}
+	if flags&0x10 == 0 {
+		description += fmt.Sprintf(", value %d", poke[3])
+	} else {
+		description += ", value asked to the user"
+	}
+	if flags&0x20 == 0 {
+		description += fmt.Sprintf(" (original value %d)", poke[4])
+	}
+	return description
+}
+
+// decodeText decodes an ISO 8859-1 string
+func decodeText(text []byte) string {
+	decodedBytes, err := charmap.ISO8859_1.NewDecoder().Bytes(text)
+	if err != nil {
+		return string(text)
+	}
+	return string(decodedBytes)
+}
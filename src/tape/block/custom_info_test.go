@@ -0,0 +1,163 @@
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// customInfo returns the content of a Custom Info block
+func customInfo(identification string, infoLength uint32, info []byte) []byte {
+	content := []byte(identification)
+	for len(content) < 16 {
+		content = append(content, ' ')
+	}
+	content = binary.LittleEndian.AppendUint32(content, infoLength)
+	return append(content, info...)
+}
+
+// readCustomInfo reads a Custom Info block with the given info
+func readCustomInfo(t *testing.T, identification string, info []byte) *CustomInfo {
+	t.Helper()
+	b, err := NewBlock(0x35, testFile(t, customInfo(identification, uint32(len(info)), info)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b.(*CustomInfo)
+}
+
+func TestCustomInfoRead(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        []byte
+		identification string
+		infoLen        int
+		err            string
+	}{
+		{"empty", customInfo("Instructions", 0, nil), "Instructions", 0, ""},
+		{"info", customInfo("Instructions", 4, []byte("LOAD")), "Instructions", 4, ""},
+		{"truncated", customInfo("Instructions", 5, []byte("LOAD")), "", 0, "custom info: block length 5 exceeds the 4 bytes left in the file"},
+		{"huge length", customInfo("Instructions", 0xffffffff, []byte("LOAD")), "", 0, "exceeds the 4 bytes left in the file"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := NewBlock(0x35, testFile(t, test.content))
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := b.(*CustomInfo)
+			if c.identification != test.identification {
+				t.Errorf("identification %q, expected %q", c.identification, test.identification)
+			}
+			if len(c.info) != test.infoLen {
+				t.Errorf("%d bytes of info, expected %d", len(c.info), test.infoLen)
+			}
+		})
+	}
+}
+
+func TestCustomInfoInfo(t *testing.T) {
+	screen := append([]byte{5, 'T', 'i', 't', 'l', 'e', 2}, make([]byte, SpectrumScreenSize)...)
+	pokes := []byte{4, 'G', 'a', 'm', 'e', 1, 5, 'L', 'i', 'v', 'e', 's', 2,
+		0x08, 0x10, 0x80, 0xff, 0x03,
+		0x31, 0x20, 0x80, 0x00, 0x00}
+
+	tests := []struct {
+		name           string
+		identification string
+		info           []byte
+		details        [][]string
+	}{
+		{
+			name:           "instructions",
+			identification: CustomInfoInstructions,
+			info:           []byte("LOAD \"\"\rPress \xa3"),
+			details:        [][]string{{"Instructions", "LOAD \"\""}, {"", "Press £"}},
+		},
+		{
+			name:           "POKEs",
+			identification: CustomInfoPokes,
+			info:           pokes,
+			details: [][]string{
+				{"Description", "Game"},
+				{"Trainer 1", "Lives"},
+				{"Trainer 1 POKE 1", "address 32784, value 255 (original value 3)"},
+				{"Trainer 1 POKE 2", "RAM page 1, address 32800, value asked to the user"},
+			},
+		},
+		{
+			name:           "truncated POKEs",
+			identification: CustomInfoPokes,
+			info:           pokes[:20],
+			details:        [][]string{{"Error", "truncated POKEs info"}},
+		},
+		{
+			name:           "picture",
+			identification: CustomInfoPicture,
+			info:           []byte{1, 4, 'L', 'o', 'g', 'o', 0xff, 0xd8},
+			details:        [][]string{{"Description", "Logo"}},
+		},
+		{
+			name:           "Spectrum screen",
+			identification: CustomInfoSpectrumScreen,
+			info:           screen,
+			details:        [][]string{{"Description", "Title"}, {"Border colour", "2 (red)"}},
+		},
+		{
+			name:           "Spectrum screen without border",
+			identification: CustomInfoSpectrumScreen,
+			info:           screen[:len(screen)-1],
+			details:        [][]string{{"Error", "Spectrum Screen info of 6912 bytes instead of 6913"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := readCustomInfo(t, test.identification, test.info)
+			if details := c.Info()[2:]; !reflect.DeepEqual(details, test.details) {
+				t.Errorf("info %q, expected %q", details, test.details)
+			}
+		})
+	}
+}
+
+func TestCustomInfoPayload(t *testing.T) {
+	screen := make([]byte, SpectrumScreenSize)
+	screen[0], screen[SpectrumScreenSize-1] = 0x55, 0x38
+	screenInfo := append([]byte{5, 'T', 'i', 't', 'l', 'e', 2}, screen...)
+
+	tests := []struct {
+		name           string
+		identification string
+		info           []byte
+		data           []byte
+		extension      string
+	}{
+		{"instructions", CustomInfoInstructions, []byte("LOAD \"\"\rRUN"), []byte("LOAD \"\"\nRUN"), "txt"},
+		{"gif picture", CustomInfoPicture, []byte{0, 1, 'A', 'G', 'I', 'F'}, []byte("GIF"), "gif"},
+		{"jpg picture", CustomInfoPicture, []byte{1, 1, 'A', 0xff, 0xd8}, []byte{0xff, 0xd8}, "jpg"},
+		{"unknown picture format", CustomInfoPicture, []byte{2, 1, 'A', 0xff}, []byte{2, 1, 'A', 0xff}, "bin"},
+		{"Spectrum screen", CustomInfoSpectrumScreen, screenInfo, screen, "scr"},
+		{"Spectrum screen too long", CustomInfoSpectrumScreen, append(screenInfo, 0), append(screenInfo, 0), "bin"},
+		{"POKEs", CustomInfoPokes, []byte{0, 0}, []byte{0, 0}, "bin"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := readCustomInfo(t, test.identification, test.info)
+			data, extension := c.Payload()
+			if !bytes.Equal(data, test.data) || extension != test.extension {
+				t.Errorf("payload of %d bytes with extension %q, expected %d bytes with extension %q", len(data), extension, len(test.data), test.extension)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package block
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// pureDataBlock returns the content of a Pure Data Block
+func pureDataBlock(zeroPulseLength int, onePulseLength int, lastByteBitsUsed byte, data []byte) []byte {
+	content := binary.LittleEndian.AppendUint16(nil, uint16(zeroPulseLength))
+	content = binary.LittleEndian.AppendUint16(content, uint16(onePulseLength))
+	content = append(content, lastByteBitsUsed)
+	content = binary.LittleEndian.AppendUint16(content, 0) // Pause
+	content = append(content, byte(len(data)), byte(len(data)>>8), byte(len(data)>>16))
+	return append(content, data...)
+}
+
+// turboSpeedDataBlock returns the content of a Turbo Speed Data Block
+func turboSpeedDataBlock(pilotPulsesNb int, zeroPulseLength int, onePulseLength int, lastByteBitsUsed byte, data []byte) []byte {
+	content := binary.LittleEndian.AppendUint16(nil, 2168) // Pilot pulse
+	content = binary.LittleEndian.AppendUint16(content, 667)
+	content = binary.LittleEndian.AppendUint16(content, 735)
+	content = binary.LittleEndian.AppendUint16(content, uint16(zeroPulseLength))
+	content = binary.LittleEndian.AppendUint16(content, uint16(onePulseLength))
+	content = binary.LittleEndian.AppendUint16(content, uint16(pilotPulsesNb))
+	content = append(content, lastByteBitsUsed)
+	content = binary.LittleEndian.AppendUint16(content, 0) // Pause
+	content = append(content, byte(len(data)), byte(len(data)>>8), byte(len(data)>>16))
+	return append(content, data...)
+}
+
+func TestDataBlocksPulses(t *testing.T) {
+	const zero, one = 855, 1710
+	data := []byte{0x00, 0xA5}
+	tests := []struct {
+		name             string
+		lastByteBitsUsed byte
+		pulsesNb         int   // Data pulses
+		lastPulses       []int // Lengths of the last data pulses
+	}{
+		{"1 bit used", 1, 18, []int{one, one}},
+		{"2 bits used", 2, 20, []int{zero, zero}},
+		{"3 bits used", 3, 22, []int{one, one}},
+		{"4 bits used", 4, 24, []int{zero, zero}},
+		{"5 bits used", 5, 26, []int{zero, zero}},
+		{"6 bits used", 6, 28, []int{one, one}},
+		{"7 bits used", 7, 30, []int{zero, zero}},
+		{"8 bits used", 8, 32, []int{one, one}},
+		{"0 means 8 bits used", 0, 32, []int{one, one}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blocks := []struct {
+				id       byte
+				content  []byte
+				pulsesNb int
+			}{
+				{0x14, pureDataBlock(zero, one, test.lastByteBitsUsed, data), test.pulsesNb},
+				{0x11, turboSpeedDataBlock(10, zero, one, test.lastByteBitsUsed, data), 12 + test.pulsesNb},
+			}
+			for _, tb := range blocks {
+				b, err := NewBlock(tb.id, testFile(t, tb.content))
+				if err != nil {
+					t.Fatal(err)
+				}
+				pulses := b.Pulses()
+				if len(pulses) != tb.pulsesNb {
+					t.Fatalf("%s: %d pulses, expected %d", b.Name(), len(pulses), tb.pulsesNb)
+				}
+				last := pulses[len(pulses)-len(test.lastPulses):]
+				for i, length := range test.lastPulses {
+					if last[i].Length != length {
+						t.Errorf("%s: last pulses %v, expected %v", b.Name(), last, test.lastPulses)
+						break
+					}
+				}
+				_, bitsNb, _, _ := b.(DataBits).DataBits()
+				if bitsNb*2 != test.pulsesNb {
+					t.Errorf("%s: %d data bits, expected %d", b.Name(), bitsNb, test.pulsesNb/2)
+				}
+			}
+		})
+	}
+}
+
+func TestTailPulses(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      byte
+		content []byte
+		length  int
+	}{
+		{"standard speed data block", 0x10, []byte{0, 0, 2, 0, 0xff, 0x01}, StandardOneBitPulseLength},
+		{"turbo speed data block", 0x11, turboSpeedDataBlock(10, 500, 1000, 8, []byte{0xff, 0x01}), 1000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := NewBlock(test.id, testFile(t, test.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tail := b.(CompatibilityTail).TailPulses()
+			if len(tail) != 64 {
+				t.Fatalf("%d tail pulses, expected 64", len(tail))
+			}
+			for _, pulse := range tail {
+				if pulse.Length != test.length || pulse.Edge != EdgeToggle {
+					t.Fatalf("tail pulse %+v, expected length %d", pulse, test.length)
+				}
+			}
+		})
+	}
+}
+
+func TestEmptyDataBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       byte
+		content  []byte
+		pulsesNb int
+	}{
+		{"standard speed data block", 0x10, []byte{0, 0, 0, 0}, StandardDataPilotToneLength + 2},
+		{"turbo speed data block", 0x11, turboSpeedDataBlock(10, 855, 1710, 8, nil), 12},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := NewBlock(test.id, testFile(t, test.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pulsesNb := len(b.Pulses()); pulsesNb != test.pulsesNb {
+				t.Errorf("%d pulses, expected %d", pulsesNb, test.pulsesNb)
+			}
+		})
+	}
+
+	if pulsesNb := len(NewStandardSpeedDataBlock(nil, 1000).Pulses()); pulsesNb != StandardDataPilotToneLength+2 {
+		t.Errorf("%d pulses of a new block without data, expected %d", pulsesNb, StandardDataPilotToneLength+2)
+	}
+}
@@ -70,15 +70,19 @@ func (d *DirectRecording) Info() [][]string {
 func (d *DirectRecording) Pulses() []Pulse {
 	pulses := make([]Pulse, 0)
 
+	// Samples give absolute levels: each pulse forces its level
 	currentPulse := Pulse{}
-	for _, samples := range d.samplesData {
-		for i := 128; i >= 1; i = i / 2 { // Iterate over every bit
-			level := int(samples)&i > 0
-			if currentPulse.Level != level {
+	for j, samples := range d.samplesData {
+		for i := 128; i >= lastBit(j, len(d.samplesData), d.lastByteBitsUsed); i = i / 2 { // Iterate over every used bit
+			edge := EdgeLow
+			if int(samples)&i > 0 {
+				edge = EdgeHigh
+			}
+			if currentPulse.Edge != edge {
 				if currentPulse.Length > 0 {
 					pulses = append(pulses, currentPulse)
 				}
-				currentPulse = Pulse{Level: level}
+				currentPulse = Pulse{Edge: edge}
 			}
 			currentPulse.Length += d.nbTstatePerSample
 		}
@@ -0,0 +1,91 @@
+package block
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+)
+
+var EmulationFlags map[uint16]string
+
+var VideoSynchronisations map[uint16]string
+
+func init() {
+	EmulationFlags = map[uint16]string{
+		0x0001: "R register emulation",
+		0x0002: "LDIR emulation",
+		0x0004: "High resolution colour emulation",
+		0x0020: "Fast loading with ROM load routine",
+		0x0040: "Border emulation",
+		0x0080: "Screen refresh mode",
+		0x0100: "Start playing the tape immediately",
+		0x0200: "Auto type LOAD\"\" or press ENTER",
+	}
+
+	VideoSynchronisations = map[uint16]string{
+		0x00: "Normal",
+		0x01: "High",
+		0x02: "Unknown",
+		0x03: "Low",
+	}
+}
+
+// EmulationInfo - ID 34
+// Deprecated since TZX 1.20
+type EmulationInfo struct {
+	flags              uint16
+	refreshDelay       int
+	interruptFrequency int
+}
+
+func (e *EmulationInfo) Id() byte {
+	return 0x34
+}
+
+func (e *EmulationInfo) Name() string {
+	return "Emulation info"
+}
+
+func (e *EmulationInfo) Read(tzxFile *os.File) error {
+	var data struct {
+		Flags              uint16
+		RefreshDelay       uint8
+		InterruptFrequency uint16
+		Reserved           [3]byte
+	}
+	if err := binary.Read(tzxFile, binary.LittleEndian, &data); err != nil {
+		return err
+	}
+	e.flags = data.Flags
+	e.refreshDelay = int(data.RefreshDelay)
+	e.interruptFrequency = int(data.InterruptFrequency)
+	return nil
+}
+
+func (e *EmulationInfo) Info() [][]string {
+	info := [][]string{
+		{"Screen refresh delay", strconv.Itoa(e.refreshDelay)},
+		{"Interrupt frequency", strconv.Itoa(e.interruptFrequency) + " Hz"},
+		{"Video synchronisation", VideoSynchronisations[(e.flags>>3)&0x03]},
+	}
+	for flag := uint16(0x0001); flag <= 0x0200; flag <<= 1 {
+		name, ok := EmulationFlags[flag]
+		if !ok {
+			continue
+		}
+		value := "Off"
+		if e.flags&flag > 0 {
+			value = "On"
+		}
+		info = append(info, []string{name, value})
+	}
+	return info
+}
+
+func (e *EmulationInfo) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (e *EmulationInfo) PauseDuration() int {
+	return 0
+}
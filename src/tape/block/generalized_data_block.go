@@ -0,0 +1,271 @@
+package block
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var SymbolPolarities map[byte]string
+
+func init() {
+	SymbolPolarities = map[byte]string{
+		0x00: "opposite to the current level",
+		0x01: "same as the current level",
+		0x02: "force low level",
+		0x03: "force high level",
+	}
+}
+
+// GeneralizedDataBlock - ID 19
+type GeneralizedDataBlock struct {
+	pauseAfterBlock   int
+	pilotSymbolsNb    int
+	pilotMaxPulses    int
+	pilotAlphabetSize int
+	dataSymbolsNb     int
+	dataMaxPulses     int
+	dataAlphabetSize  int
+	pilotSymbols      []Symbol
+	pilotStream       []SymbolRepetition
+	dataSymbols       []Symbol
+	dataStream        []byte
+}
+
+// Symbol is an entry of a symbol definition table of a Generalized Data Block
+type Symbol struct {
+	flags  byte
+	pulses []int
+}
+
+// SymbolRepetition is an entry of the pilot and sync data stream of a
+// Generalized Data Block
+type SymbolRepetition struct {
+	symbol      byte
+	repetitions int
+}
+
+func (g *GeneralizedDataBlock) Id() byte {
+	return 0x19
+}
+
+func (g *GeneralizedDataBlock) Name() string {
+	return "Generalized Data Block"
+}
+
+func (g *GeneralizedDataBlock) Read(tzxFile *os.File) error {
+	var header struct {
+		BlockLength       uint32
+		PauseAfterBlock   uint16
+		PilotSymbolsNb    uint32
+		PilotMaxPulses    uint8
+		PilotAlphabetSize uint8
+		DataSymbolsNb     uint32
+		DataMaxPulses     uint8
+		DataAlphabetSize  uint8
+	}
+	if err := binary.Read(tzxFile, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	// The block length counts the bytes following the length field
+	headerEnd, err := tzxFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	blockEnd := headerEnd - int64(binary.Size(header)) + 4 + int64(header.BlockLength)
+
+	g.pauseAfterBlock = int(header.PauseAfterBlock)
+	g.pilotSymbolsNb = int(header.PilotSymbolsNb)
+	g.pilotMaxPulses = int(header.PilotMaxPulses)
+	g.pilotAlphabetSize = alphabetSize(header.PilotAlphabetSize)
+	g.dataSymbolsNb = int(header.DataSymbolsNb)
+	g.dataMaxPulses = int(header.DataMaxPulses)
+	g.dataAlphabetSize = alphabetSize(header.DataAlphabetSize)
+
+	if g.pilotSymbolsNb > 0 {
+		symbols, err := readSymbols(tzxFile, g.pilotAlphabetSize, g.pilotMaxPulses)
+		if err != nil {
+			return err
+		}
+		g.pilotSymbols = symbols
+
+		for i := 0; i < g.pilotSymbolsNb; i++ {
+			var prle struct {
+				Symbol      uint8
+				Repetitions uint16
+			}
+			if err := binary.Read(tzxFile, binary.LittleEndian, &prle); err != nil {
+				return err
+			}
+			if int(prle.Symbol) >= g.pilotAlphabetSize {
+				return fmt.Errorf("generalized data block: undefined pilot/sync symbol %d", prle.Symbol)
+			}
+			g.pilotStream = append(g.pilotStream, SymbolRepetition{
+				symbol:      prle.Symbol,
+				repetitions: int(prle.Repetitions),
+			})
+		}
+	}
+
+	if g.dataSymbolsNb > 0 {
+		symbols, err := readSymbols(tzxFile, g.dataAlphabetSize, g.dataMaxPulses)
+		if err != nil {
+			return err
+		}
+		g.dataSymbols = symbols
+
+		dataStreamSize := (int64(g.dataSymbolBits())*int64(g.dataSymbolsNb) + 7) / 8
+		pos, err := tzxFile.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if pos+dataStreamSize > blockEnd {
+			return errors.New("generalized data block: data stream exceeds the block length")
+		}
+		if err := checkRemainingSize(tzxFile, dataStreamSize); err != nil {
+			return err
+		}
+		dataStream := make([]byte, dataStreamSize)
+		if _, err := io.ReadFull(tzxFile, dataStream); err != nil {
+			return err
+		}
+		g.dataStream = dataStream
+
+		for i := 0; i < g.dataSymbolsNb; i++ {
+			if symbol := g.dataSymbol(i); symbol >= g.dataAlphabetSize {
+				return fmt.Errorf("generalized data block: undefined data symbol %d", symbol)
+			}
+		}
+	}
+
+	// Skip the bytes left in the block, so the next blocks are read where
+	// they start even if the block content is shorter than its length
+	pos, err := tzxFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if pos > blockEnd {
+		return errors.New("generalized data block: block content exceeds the block length")
+	}
+	_, err = tzxFile.Seek(blockEnd, io.SeekStart)
+	return err
+}
+
+func (g *GeneralizedDataBlock) Info() [][]string {
+	info := [][]string{
+		{"Pause after block", fmt.Sprintf("%d ms", g.pauseAfterBlock)},
+		{"Pilot/sync symbols number", strconv.Itoa(g.pilotSymbolsNb)},
+		{"Pilot/sync max pulses per symbol", strconv.Itoa(g.pilotMaxPulses)},
+		{"Pilot/sync alphabet size", strconv.Itoa(g.pilotAlphabetSize)},
+		{"Data symbols number", strconv.Itoa(g.dataSymbolsNb)},
+		{"Data max pulses per symbol", strconv.Itoa(g.dataMaxPulses)},
+		{"Data alphabet size", strconv.Itoa(g.dataAlphabetSize)},
+		{"Data stream length", strconv.Itoa(len(g.dataStream))},
+	}
+	for i, s := range g.pilotSymbols {
+		info = append(info, []string{fmt.Sprintf("Pilot/sync symbol %d", i), s.String()})
+	}
+	for i, s := range g.dataSymbols {
+		info = append(info, []string{fmt.Sprintf("Data symbol %d", i), s.String()})
+	}
+	return info
+}
+
+func (g *GeneralizedDataBlock) Pulses() []Pulse {
+	pulses := make([]Pulse, 0)
+
+	// Generate pilot and sync pulses
+	for _, prle := range g.pilotStream {
+		for i := 0; i < prle.repetitions; i++ {
+			pulses = g.pilotSymbols[prle.symbol].appendPulses(pulses)
+		}
+	}
+
+	// Generate data pulses
+	for i := 0; i < g.dataSymbolsNb; i++ {
+		pulses = g.dataSymbols[g.dataSymbol(i)].appendPulses(pulses)
+	}
+
+	return pulses
+}
+
+func (g *GeneralizedDataBlock) PauseDuration() int {
+	return g.pauseAfterBlock
+}
+
+// dataSymbolBits returns the number of bits needed to store one data symbol
+func (g *GeneralizedDataBlock) dataSymbolBits() int {
+	bits := 0
+	for 1<<bits < g.dataAlphabetSize {
+		bits++
+	}
+	return bits
+}
+
+// dataSymbol returns the data symbol at the given position of the data stream
+func (g *GeneralizedDataBlock) dataSymbol(i int) int {
+	bitsPerSymbol := g.dataSymbolBits()
+	symbol := 0
+	for j := 0; j < bitsPerSymbol; j++ { // Symbols are stored MSb first
+		bitPos := i*bitsPerSymbol + j
+		bit := (g.dataStream[bitPos/8] >> (7 - bitPos%8)) & 1
+		symbol = symbol<<1 | int(bit)
+	}
+	return symbol
+}
+
+// appendPulses appends the pulses of the symbol to the given pulses. The
+// symbol polarity applies to the edge of its first pulse.
+func (s Symbol) appendPulses(pulses []Pulse) []Pulse {
+	for i, length := range s.pulses {
+		if length == 0 { // A zero length pulse ends the symbol
+			break
+		}
+		pulse := Pulse{Length: length}
+		if i == 0 {
+			pulse.Edge = Edge(s.flags & 0x03)
+		}
+		pulses = append(pulses, pulse)
+	}
+	return pulses
+}
+
+func (s Symbol) String() string {
+	lengths := make([]string, 0)
+	for _, length := range s.pulses {
+		if length == 0 {
+			break
+		}
+		lengths = append(lengths, strconv.Itoa(length))
+	}
+	return fmt.Sprintf("%s, pulses: %s", SymbolPolarities[s.flags&0x03], strings.Join(lengths, ", "))
+}
+
+// readSymbols reads a symbol definition table
+func readSymbols(tzxFile *os.File, alphabetSize int, maxPulses int) ([]Symbol, error) {
+	symbols := make([]Symbol, 0, alphabetSize)
+	for i := 0; i < alphabetSize; i++ {
+		symbolDef := make([]byte, 1+maxPulses*2)
+		if _, err := io.ReadFull(tzxFile, symbolDef); err != nil {
+			return nil, err
+		}
+		symbol := Symbol{flags: symbolDef[0]}
+		for j := 0; j < maxPulses; j++ {
+			symbol.pulses = append(symbol.pulses, int(binary.LittleEndian.Uint16(symbolDef[1+j*2:3+j*2])))
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, nil
+}
+
+// alphabetSize decodes an alphabet size. 0 means 256 symbols
+func alphabetSize(size uint8) int {
+	if size == 0 {
+		return 256
+	}
+	return int(size)
+}
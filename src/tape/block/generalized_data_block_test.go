@@ -0,0 +1,232 @@
+package block
+
+import (
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// generalizedData holds the fields of a Generalized Data Block to build
+type generalizedData struct {
+	pilotSymbolsNb    uint32
+	pilotMaxPulses    byte
+	pilotAlphabetSize byte
+	pilotSymbols      []byte
+	pilotStream       []byte
+	dataSymbolsNb     uint32
+	dataMaxPulses     byte
+	dataAlphabetSize  byte
+	dataSymbols       []byte
+	dataStream        []byte
+	padding           int
+}
+
+// bytes returns the block content following its ID. The block length
+// counts padding bytes, which are not written
+func (g generalizedData) bytes() []byte {
+	content := binary.LittleEndian.AppendUint16(nil, 0)
+	content = binary.LittleEndian.AppendUint32(content, g.pilotSymbolsNb)
+	content = append(content, g.pilotMaxPulses, g.pilotAlphabetSize)
+	content = binary.LittleEndian.AppendUint32(content, g.dataSymbolsNb)
+	content = append(content, g.dataMaxPulses, g.dataAlphabetSize)
+	content = append(content, g.pilotSymbols...)
+	content = append(content, g.pilotStream...)
+	content = append(content, g.dataSymbols...)
+	content = append(content, g.dataStream...)
+	length := binary.LittleEndian.AppendUint32(nil, uint32(len(content)+g.padding))
+	return append(length, content...)
+}
+
+// symbol encodes a symbol definition
+func symbol(flags byte, pulses ...uint16) []byte {
+	def := []byte{flags}
+	for _, pulse := range pulses {
+		def = binary.LittleEndian.AppendUint16(def, pulse)
+	}
+	return def
+}
+
+// prle encodes a pilot and sync data stream entry
+func prle(symbol byte, repetitions uint16) []byte {
+	return binary.LittleEndian.AppendUint16([]byte{symbol}, repetitions)
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, part := range parts {
+		b = append(b, part...)
+	}
+	return b
+}
+
+func TestGeneralizedDataBlockRead(t *testing.T) {
+	alphabet256 := make([]byte, 0)
+	for i := 0; i < 256; i++ {
+		alphabet256 = append(alphabet256, symbol(0, uint16(i+1))...)
+	}
+
+	tests := []struct {
+		name    string
+		block   generalizedData
+		content func([]byte) []byte
+		pulses  []Pulse
+		err     string
+	}{
+		{
+			name: "pilot and data symbols",
+			block: generalizedData{
+				pilotSymbolsNb:    2,
+				pilotMaxPulses:    2,
+				pilotAlphabetSize: 2,
+				pilotSymbols:      concat(symbol(0, 100, 200), symbol(3, 300, 0)),
+				pilotStream:       concat(prle(0, 2), prle(1, 1)),
+				dataSymbolsNb:     3,
+				dataMaxPulses:     1,
+				dataAlphabetSize:  2,
+				dataSymbols:       concat(symbol(0, 400), symbol(1, 500)),
+				dataStream:        []byte{0x40},
+			},
+			pulses: []Pulse{
+				{100, EdgeToggle}, {200, EdgeToggle}, {100, EdgeToggle}, {200, EdgeToggle},
+				{300, EdgeHigh},
+				{400, EdgeToggle}, {500, EdgeNone}, {400, EdgeToggle},
+			},
+		},
+		{
+			name: "256 symbols alphabet",
+			block: generalizedData{
+				dataSymbolsNb: 2,
+				dataMaxPulses: 1,
+				dataSymbols:   alphabet256,
+				dataStream:    []byte{0xff, 0x00},
+			},
+			pulses: []Pulse{{256, EdgeToggle}, {1, EdgeToggle}},
+		},
+		{
+			name: "bytes left in the block",
+			block: generalizedData{
+				dataSymbolsNb:    1,
+				dataMaxPulses:    1,
+				dataAlphabetSize: 1,
+				dataSymbols:      symbol(0, 400),
+				dataStream:       []byte{0x00},
+				padding:          2,
+			},
+			content: func(b []byte) []byte { return append(b, 0, 0) },
+			pulses:  []Pulse{{400, EdgeToggle}},
+		},
+		{
+			name: "undefined pilot symbol",
+			block: generalizedData{
+				pilotSymbolsNb:    1,
+				pilotMaxPulses:    1,
+				pilotAlphabetSize: 2,
+				pilotSymbols:      concat(symbol(0, 100), symbol(0, 200)),
+				pilotStream:       prle(2, 1),
+			},
+			err: "undefined pilot/sync symbol 2",
+		},
+		{
+			name: "undefined data symbol",
+			block: generalizedData{
+				dataSymbolsNb:    2,
+				dataMaxPulses:    1,
+				dataAlphabetSize: 3,
+				dataSymbols:      concat(symbol(0, 100), symbol(0, 200), symbol(0, 300)),
+				dataStream:       []byte{0x30},
+			},
+			err: "undefined data symbol 3",
+		},
+		{
+			name: "data stream exceeding the block length",
+			block: generalizedData{
+				dataSymbolsNb:    16,
+				dataMaxPulses:    1,
+				dataAlphabetSize: 2,
+				dataSymbols:      concat(symbol(0, 100), symbol(0, 200)),
+				dataStream:       []byte{0x00, 0x00},
+				padding:          -1,
+			},
+			err: "data stream exceeds the block length",
+		},
+		{
+			name: "truncated symbol table",
+			block: generalizedData{
+				pilotSymbolsNb:    1,
+				pilotMaxPulses:    2,
+				pilotAlphabetSize: 2,
+				pilotSymbols:      concat(symbol(0, 100, 200), []byte{0, 100}),
+			},
+			err: io.ErrUnexpectedEOF.Error(),
+		},
+		{
+			name: "truncated prle",
+			block: generalizedData{
+				pilotSymbolsNb:    2,
+				pilotMaxPulses:    1,
+				pilotAlphabetSize: 1,
+				pilotSymbols:      symbol(0, 100),
+				pilotStream:       concat(prle(0, 10), []byte{0}),
+			},
+			err: io.ErrUnexpectedEOF.Error(),
+		},
+		{
+			name: "truncated data stream",
+			block: generalizedData{
+				dataSymbolsNb:    16,
+				dataMaxPulses:    1,
+				dataAlphabetSize: 2,
+				dataSymbols:      concat(symbol(0, 100), symbol(0, 200)),
+				dataStream:       []byte{0x00, 0x00},
+			},
+			content: func(b []byte) []byte { return b[:len(b)-1] },
+			err:     "exceeds the 1 bytes left in the file",
+		},
+		{
+			name: "huge data symbols number",
+			block: generalizedData{
+				dataSymbolsNb:    0xffffffff,
+				dataMaxPulses:    1,
+				dataAlphabetSize: 2,
+				dataSymbols:      concat(symbol(0, 100), symbol(0, 200)),
+				padding:          0x7fffffff,
+			},
+			err: "exceeds the 0 bytes left in the file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content := test.block.bytes()
+			if test.content != nil {
+				content = test.content(content)
+			}
+			f := testFile(t, content)
+
+			g := &GeneralizedDataBlock{}
+			err := g.Read(f)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pos, err := f.Seek(0, io.SeekCurrent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pos != int64(len(content)) {
+				t.Errorf("block read up to offset %d, expected %d", pos, len(content))
+			}
+			if pulses := g.Pulses(); !reflect.DeepEqual(pulses, test.pulses) {
+				t.Errorf("pulses %v, expected %v", pulses, test.pulses)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const GlueSignature = "XTape!"
+
+// Glue - ID 5A
+// This block is the header of a TZX file merged to the end of another one.
+type Glue struct {
+	majorVersion int
+	minorVersion int
+}
+
+func (g *Glue) Id() byte {
+	return 0x5A
+}
+
+func (g *Glue) Name() string {
+	return "Glue block"
+}
+
+func (g *Glue) Read(tzxFile *os.File) error {
+	header := make([]byte, 9)
+	if _, err := io.ReadFull(tzxFile, header); err != nil {
+		return err
+	}
+	if string(header[0:6]) != GlueSignature || header[6] != 0x1a {
+		return errors.New("not a valid glue block (no TZX signature)")
+	}
+	g.majorVersion = int(header[7])
+	g.minorVersion = int(header[8])
+	return nil
+}
+
+func (g *Glue) Info() [][]string {
+	return [][]string{
+		{"TZX Tape Version", fmt.Sprintf("%d.%d", g.majorVersion, g.minorVersion)},
+	}
+}
+
+func (g *Glue) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (g *Glue) PauseDuration() int {
+	return 0
+}
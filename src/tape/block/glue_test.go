@@ -0,0 +1,38 @@
+package block
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGlueRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		info    [][]string
+		err     string
+	}{
+		{"glue", []byte("XTape!\x1a\x01\x14"), [][]string{{"TZX Tape Version", "1.20"}}, ""},
+		{"no signature", []byte("ZXTape\x1a\x01\x14"), nil, "not a valid glue block"},
+		{"truncated", []byte("XTape!\x1a"), nil, "EOF"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := NewBlock(0x5A, testFile(t, test.content))
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info := b.Info(); !reflect.DeepEqual(info, test.info) {
+				t.Errorf("info %q, expected %q", info, test.info)
+			}
+		})
+	}
+}
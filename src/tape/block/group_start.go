@@ -49,3 +49,8 @@ func (g *GroupStart) Pulses() []Pulse {
 func (g *GroupStart) PauseDuration() int {
 	return 0
 }
+
+// GroupName returns the name of the group
+func (g *GroupStart) GroupName() string {
+	return g.name
+}
@@ -0,0 +1,48 @@
+package block
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+)
+
+// Jump - ID 23
+type Jump struct {
+	offset int
+}
+
+func (j *Jump) Id() byte {
+	return 0x23
+}
+
+func (j *Jump) Name() string {
+	return "Jump to block"
+}
+
+func (j *Jump) Read(tzxFile *os.File) error {
+	var offset int16
+	if err := binary.Read(tzxFile, binary.LittleEndian, &offset); err != nil {
+		return err
+	}
+	j.offset = int(offset)
+	return nil
+}
+
+func (j *Jump) Info() [][]string {
+	return [][]string{
+		{"Relative jump value", strconv.Itoa(j.offset)},
+	}
+}
+
+func (j *Jump) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (j *Jump) PauseDuration() int {
+	return 0
+}
+
+// Offset returns the jump offset relative to this block
+func (j *Jump) Offset() int {
+	return j.offset
+}
@@ -0,0 +1,145 @@
+package block
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// KansasCityStandard - ID 4B
+// Used by MSX tapes in TSX format
+type KansasCityStandard struct {
+	pauseAfterBlock   int
+	pilotPulseLength  int
+	pilotPulsesNb     int
+	zeroPulseLength   int
+	onePulseLength    int
+	zeroBitPulsesNb   int
+	oneBitPulsesNb    int
+	leadingBitsNb     int
+	leadingBitsValue  bool
+	trailingBitsNb    int
+	trailingBitsValue bool
+	msbFirst          bool
+	data              []byte
+}
+
+func (k *KansasCityStandard) Id() byte {
+	return 0x4B
+}
+
+func (k *KansasCityStandard) Name() string {
+	return "Kansas City Standard"
+}
+
+func (k *KansasCityStandard) Read(tzxFile *os.File) error {
+	var header struct {
+		BlockLength      uint32
+		PauseAfterBlock  uint16
+		PilotPulseLength uint16
+		PilotPulsesNb    uint16
+		ZeroPulseLength  uint16
+		OnePulseLength   uint16
+		BitPulsesNb      uint8
+		BitsConfig       uint8
+	}
+	if err := binary.Read(tzxFile, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	k.pauseAfterBlock = int(header.PauseAfterBlock)
+	k.pilotPulseLength = int(header.PilotPulseLength)
+	k.pilotPulsesNb = int(header.PilotPulsesNb)
+	k.zeroPulseLength = int(header.ZeroPulseLength)
+	k.onePulseLength = int(header.OnePulseLength)
+	k.zeroBitPulsesNb = pulsesPerBit(header.BitPulsesNb >> 4)
+	k.oneBitPulsesNb = pulsesPerBit(header.BitPulsesNb & 0x0F)
+	k.leadingBitsNb = int(header.BitsConfig >> 6)
+	k.leadingBitsValue = header.BitsConfig&0x20 > 0
+	k.trailingBitsNb = int(header.BitsConfig>>3) & 0x03
+	k.trailingBitsValue = header.BitsConfig&0x04 > 0
+	k.msbFirst = header.BitsConfig&0x01 > 0
+
+	if header.BlockLength < 12 {
+		return fmt.Errorf("kansas city standard: invalid block length")
+	}
+	data := make([]byte, header.BlockLength-12)
+	if _, err := tzxFile.Read(data); err != nil {
+		return err
+	}
+	k.data = data
+
+	return nil
+}
+
+func (k *KansasCityStandard) Info() [][]string {
+	return [][]string{
+		{"Pause after block", fmt.Sprintf("%d ms", k.pauseAfterBlock)},
+		{"PILOT pulse length", strconv.Itoa(k.pilotPulseLength)},
+		{"PILOT pulses number", strconv.Itoa(k.pilotPulsesNb)},
+		{"ZERO pulse length", strconv.Itoa(k.zeroPulseLength)},
+		{"ONE pulse length", strconv.Itoa(k.onePulseLength)},
+		{"Pulses in a ZERO bit", strconv.Itoa(k.zeroBitPulsesNb)},
+		{"Pulses in a ONE bit", strconv.Itoa(k.oneBitPulsesNb)},
+		{"Leading bits", fmt.Sprintf("%d x %d", k.leadingBitsNb, bitValue(k.leadingBitsValue))},
+		{"Trailing bits", fmt.Sprintf("%d x %d", k.trailingBitsNb, bitValue(k.trailingBitsValue))},
+		{"Bit order", bitOrderName(k.msbFirst)},
+		{"Data length", strconv.Itoa(len(k.data))},
+	}
+}
+
+func (k *KansasCityStandard) Pulses() []Pulse {
+	pulses := make([]Pulse, 0)
+
+	// Generate pilot tone
+	for i := 0; i < k.pilotPulsesNb; i++ {
+		pulses = append(pulses, Pulse{Length: k.pilotPulseLength})
+	}
+
+	// Generate data pulses, each byte being surrounded by its start and stop bits
+	for _, dataByte := range k.data {
+		for i := 0; i < k.leadingBitsNb; i++ {
+			pulses = k.appendBit(pulses, k.leadingBitsValue)
+		}
+		for _, bit := range byteBits(dataByte, 8, k.msbFirst) {
+			pulses = k.appendBit(pulses, bit)
+		}
+		for i := 0; i < k.trailingBitsNb; i++ {
+			pulses = k.appendBit(pulses, k.trailingBitsValue)
+		}
+	}
+
+	return pulses
+}
+
+func (k *KansasCityStandard) PauseDuration() int {
+	return k.pauseAfterBlock
+}
+
+// appendBit appends the pulses of a bit
+func (k *KansasCityStandard) appendBit(pulses []Pulse, bit bool) []Pulse {
+	pulseLength, pulsesNb := k.zeroPulseLength, k.zeroBitPulsesNb
+	if bit {
+		pulseLength, pulsesNb = k.onePulseLength, k.oneBitPulsesNb
+	}
+	for i := 0; i < pulsesNb; i++ {
+		pulses = append(pulses, Pulse{Length: pulseLength})
+	}
+	return pulses
+}
+
+// pulsesPerBit decodes a number of pulses per bit. 0 means 16 pulses
+func pulsesPerBit(nb uint8) int {
+	if nb == 0 {
+		return 16
+	}
+	return int(nb)
+}
+
+// bitValue returns the numeric value of a bit
+func bitValue(bit bool) int {
+	if bit {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package block
+
+import (
+	"reflect"
+	"testing"
+)
+
+// kansasCityStandardHeader is the header of a Kansas City Standard block
+type kansasCityStandardHeader struct {
+	BlockLength      uint32
+	PauseAfterBlock  uint16
+	PilotPulseLength uint16
+	PilotPulsesNb    uint16
+	ZeroPulseLength  uint16
+	OnePulseLength   uint16
+	BitPulsesNb      uint8
+	BitsConfig       uint8
+}
+
+// repeatLength returns nb times the given pulse length
+func repeatLength(length int, nb int) []int {
+	lengths := make([]int, 0, nb)
+	for i := 0; i < nb; i++ {
+		lengths = append(lengths, length)
+	}
+	return lengths
+}
+
+func TestKansasCityStandardPulses(t *testing.T) {
+	tests := []struct {
+		name   string
+		header kansasCityStandardHeader
+		data   []byte
+		zero   []int
+		one    []int
+		pilot  []int
+		bits   string
+	}{
+		{
+			name: "LSb first with leading and trailing bits",
+			header: kansasCityStandardHeader{
+				PilotPulseLength: 100, PilotPulsesNb: 3,
+				ZeroPulseLength: 20, OnePulseLength: 10,
+				BitPulsesNb: 0x24,
+				BitsConfig:  1<<6 | 2<<3 | 0x04,
+			},
+			data:  []byte{0x01, 0xFE},
+			zero:  repeatLength(20, 2),
+			one:   repeatLength(10, 4),
+			pilot: repeatLength(100, 3),
+			bits:  "0" + "10000000" + "11" + "0" + "01111111" + "11",
+		},
+		{
+			name: "MSb first with 16 pulses bits",
+			header: kansasCityStandardHeader{
+				ZeroPulseLength: 20, OnePulseLength: 10,
+				BitPulsesNb: 0x01,
+				BitsConfig:  2<<6 | 0x20 | 0x01,
+			},
+			data: []byte{0x80},
+			zero: repeatLength(20, 16),
+			one:  repeatLength(10, 1),
+			bits: "11" + "10000000",
+		},
+		{
+			name: "no leading and trailing bits",
+			header: kansasCityStandardHeader{
+				ZeroPulseLength: 20, OnePulseLength: 10,
+				BitPulsesNb: 0x11,
+				BitsConfig:  0x20 | 0x04,
+			},
+			data: []byte{0x0F},
+			zero: repeatLength(20, 1),
+			one:  repeatLength(10, 1),
+			bits: "11110000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.header.BlockLength = uint32(12 + len(test.data))
+			b := readBlock(t, 0x4B, &test.header, test.data)
+
+			expected := append([]int{}, test.pilot...)
+			for _, bit := range test.bits {
+				if bit == '1' {
+					expected = append(expected, test.one...)
+				} else {
+					expected = append(expected, test.zero...)
+				}
+			}
+			if lengths := pulsesLengths(b.Pulses()); !reflect.DeepEqual(lengths, expected) {
+				t.Errorf("pulses lengths %v, expected %v", lengths, expected)
+			}
+		})
+	}
+}
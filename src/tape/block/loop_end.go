@@ -0,0 +1,31 @@
+package block
+
+import "os"
+
+// LoopEnd - ID 25
+type LoopEnd struct {
+}
+
+func (l *LoopEnd) Id() byte {
+	return 0x25
+}
+
+func (l *LoopEnd) Name() string {
+	return "Loop end"
+}
+
+func (l *LoopEnd) Read(tzxFile *os.File) error {
+	return nil
+}
+
+func (l *LoopEnd) Info() [][]string {
+	return [][]string{}
+}
+
+func (l *LoopEnd) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (l *LoopEnd) PauseDuration() int {
+	return 0
+}
@@ -0,0 +1,49 @@
+package block
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+)
+
+// LoopStart - ID 24
+type LoopStart struct {
+	repetitions int
+}
+
+func (l *LoopStart) Id() byte {
+	return 0x24
+}
+
+func (l *LoopStart) Name() string {
+	return "Loop start"
+}
+
+func (l *LoopStart) Read(tzxFile *os.File) error {
+	var repetitions uint16
+	if err := binary.Read(tzxFile, binary.LittleEndian, &repetitions); err != nil {
+		return err
+	}
+	l.repetitions = int(repetitions)
+	return nil
+}
+
+func (l *LoopStart) Info() [][]string {
+	return [][]string{
+		{"Number of repetitions", strconv.Itoa(l.repetitions)},
+	}
+}
+
+func (l *LoopStart) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (l *LoopStart) PauseDuration() int {
+	return 0
+}
+
+// Repetitions returns the number of times the blocks up to the next
+// loop end block must be played
+func (l *LoopStart) Repetitions() int {
+	return l.repetitions
+}
@@ -54,3 +54,13 @@ func (m *MessageBlock) Pulses() []Pulse {
 func (m *MessageBlock) PauseDuration() int {
 	return 0
 }
+
+// Message returns the message to display
+func (m *MessageBlock) Message() string {
+	return m.message
+}
+
+// DisplayDuration returns the display duration of the message in seconds
+func (m *MessageBlock) DisplayDuration() int {
+	return m.displayDuration
+}
@@ -11,6 +11,11 @@ type Pause struct {
 	pauseDuration int
 }
 
+// NewPause creates a pause of the given duration in ms
+func NewPause(pauseDuration int) *Pause {
+	return &Pause{pauseDuration: pauseDuration}
+}
+
 func (p *Pause) Id() byte {
 	return 0x20
 }
@@ -29,6 +34,11 @@ func (p *Pause) Read(tzxFile *os.File) error {
 }
 
 func (p *Pause) Info() [][]string {
+	if p.StopsTheTape() {
+		return [][]string{
+			{"Pause duration", "0 ms (stop the tape)"},
+		}
+	}
 	return [][]string{
 		{"Pause duration", fmt.Sprintf("%d ms", p.pauseDuration)},
 	}
@@ -41,3 +51,9 @@ func (p *Pause) Pulses() []Pulse {
 func (p *Pause) PauseDuration() int {
 	return p.pauseDuration
 }
+
+// StopsTheTape tells if this block is a "Stop the tape" command,
+// which is a pause of 0 ms
+func (p *Pause) StopsTheTape() bool {
+	return p.pauseDuration == 0
+}
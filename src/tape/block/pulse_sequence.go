@@ -43,14 +43,11 @@ func (p *PulseSequence) Info() [][]string {
 
 func (p *PulseSequence) Pulses() []Pulse {
 	pulses := make([]Pulse, 0)
-	level := false
 
 	for i := 0; i < len(p.pulsesLengths); i = i + 2 {
 		pulses = append(pulses, Pulse{
 			Length: int(binary.LittleEndian.Uint16(p.pulsesLengths[i : i+2])),
-			Level:  level,
 		})
-		level = !level
 	}
 
 	return pulses
@@ -80,21 +80,15 @@ func (p *PureDataBlock) Info() [][]string {
 func (p *PureDataBlock) Pulses() []Pulse {
 	pulses := make([]Pulse, 0)
 
-	for _, dataByte := range p.data {
-		for i := 128; i >= 1; i = i / 2 { // Iterate over every bit
+	for j, dataByte := range p.data {
+		for i := 128; i >= lastBit(j, len(p.data), p.lastByteBitsUsed); i = i / 2 { // Iterate over every used bit
 			pulseLength := p.zeroBitPulseLength
 			if int(dataByte)&i > 0 {
 				pulseLength = p.oneBitPulseLength
 			}
 			pulses = append(pulses, []Pulse{
-				{
-					Length: pulseLength,
-					Level:  false,
-				},
-				{
-					Length: pulseLength,
-					Level:  true,
-				},
+				{Length: pulseLength},
+				{Length: pulseLength},
 			}...)
 		}
 	}
@@ -105,3 +99,30 @@ func (p *PureDataBlock) Pulses() []Pulse {
 func (p *PureDataBlock) PauseDuration() int {
 	return p.pauseAfterBlock
 }
+
+// DataBits returns the data of the block and the number of used bits
+func (p *PureDataBlock) DataBits() ([]byte, int, int, int) {
+	return p.data, usedBitsNb(len(p.data), p.lastByteBitsUsed), p.zeroBitPulseLength, p.oneBitPulseLength
+}
+
+// lastBit returns the mask of the last used bit of the byte at position i in
+// the data. Only the lastByteBitsUsed most significant bits of the last byte
+// are used.
+func lastBit(i int, dataSize int, lastByteBitsUsed int) int {
+	if i < dataSize-1 || lastByteBitsUsed < 1 || lastByteBitsUsed > 8 {
+		return 1
+	}
+	return 1 << (8 - lastByteBitsUsed)
+}
+
+// usedBitsNb returns the number of bits used in the data, only the
+// lastByteBitsUsed most significant bits of the last byte being used
+func usedBitsNb(dataSize int, lastByteBitsUsed int) int {
+	if dataSize == 0 {
+		return 0
+	}
+	if lastByteBitsUsed < 1 || lastByteBitsUsed > 8 {
+		lastByteBitsUsed = 8
+	}
+	return (dataSize-1)*8 + lastByteBitsUsed
+}
@@ -45,11 +45,9 @@ func (p *PureTone) Info() [][]string {
 
 func (p *PureTone) Pulses() []Pulse {
 	pulses := make([]Pulse, 0)
-	level := false
 
 	for i := 0; i < p.pulsesNb; i++ {
-		pulses = append(pulses, Pulse{Length: p.onePulseLength, Level: level})
-		level = !level
+		pulses = append(pulses, Pulse{Length: p.onePulseLength})
 	}
 
 	return pulses
@@ -0,0 +1,128 @@
+package block
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PzxDataBlock - PZX DATA block (no TZX ID)
+type PzxDataBlock struct {
+	bitsNb       int
+	initialLevel bool
+	tailLength   int
+	zeroPulses   []int
+	onePulses    []int
+	data         []byte
+	content      []byte
+}
+
+// NewPzxDataBlock creates a block from the content of a PZX DATA block
+func NewPzxDataBlock(data []byte) (*PzxDataBlock, error) {
+	truncated := errors.New("pzx data block: truncated block")
+	if len(data) < 8 {
+		return nil, truncated
+	}
+
+	count := binary.LittleEndian.Uint32(data[0:4])
+	d := &PzxDataBlock{
+		bitsNb:       int(count & 0x7fffffff),
+		initialLevel: count&0x80000000 != 0,
+		tailLength:   int(binary.LittleEndian.Uint16(data[4:6])),
+		content:      data,
+	}
+	zeroPulsesNb := int(data[6])
+	onePulsesNb := int(data[7])
+
+	pos := 8
+	if pos+(zeroPulsesNb+onePulsesNb)*2 > len(data) {
+		return nil, truncated
+	}
+	for i := 0; i < zeroPulsesNb; i++ {
+		d.zeroPulses = append(d.zeroPulses, int(binary.LittleEndian.Uint16(data[pos:pos+2])))
+		pos += 2
+	}
+	for i := 0; i < onePulsesNb; i++ {
+		d.onePulses = append(d.onePulses, int(binary.LittleEndian.Uint16(data[pos:pos+2])))
+		pos += 2
+	}
+
+	dataSize := (d.bitsNb + 7) / 8
+	if pos+dataSize > len(data) {
+		return nil, truncated
+	}
+	d.data = data[pos : pos+dataSize]
+
+	return d, nil
+}
+
+func (d *PzxDataBlock) Id() byte {
+	return NoId
+}
+
+func (d *PzxDataBlock) Name() string {
+	return "PZX Data Block"
+}
+
+func (d *PzxDataBlock) Read(tzxFile *os.File) error {
+	return errors.New("pzx data block: not a TZX block")
+}
+
+func (d *PzxDataBlock) Info() [][]string {
+	return [][]string{
+		{"Bits number", strconv.Itoa(d.bitsNb)},
+		{"Initial signal level", levelName(d.initialLevel)},
+		{"Bit 0 pulses lengths", pulsesLengthsString(d.zeroPulses)},
+		{"Bit 1 pulses lengths", pulsesLengthsString(d.onePulses)},
+		{"Tail pulse length", strconv.Itoa(d.tailLength)},
+	}
+}
+
+// Pulses returns the pulses of the bits, most significant bit first, followed
+// by the tail pulse if any. The first pulse has the initial level, then the
+// level toggles at each pulse.
+func (d *PzxDataBlock) Pulses() []Pulse {
+	pulses := make([]Pulse, 0)
+	appendPulse := func(length int) {
+		edge := EdgeToggle
+		if len(pulses) == 0 {
+			edge = levelEdge(d.initialLevel)
+		}
+		pulses = append(pulses, Pulse{Length: length, Edge: edge})
+	}
+
+	for i := 0; i < d.bitsNb; i++ {
+		bitPulses := d.zeroPulses
+		if d.data[i/8]&(0x80>>(i%8)) != 0 {
+			bitPulses = d.onePulses
+		}
+		for _, length := range bitPulses {
+			appendPulse(length)
+		}
+	}
+	if d.tailLength > 0 {
+		appendPulse(d.tailLength)
+	}
+
+	return pulses
+}
+
+func (d *PzxDataBlock) PauseDuration() int {
+	return 0
+}
+
+// PzxContent returns the content of the block in a PZX file
+func (d *PzxDataBlock) PzxContent() (tag string, content []byte) {
+	return "DATA", d.content
+}
+
+// pulsesLengthsString returns the given pulses lengths separated by commas
+func pulsesLengthsString(lengths []int) string {
+	s := make([]string, len(lengths))
+	for i, length := range lengths {
+		s[i] = strconv.Itoa(length)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,87 @@
+package block
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// PzxPause - PZX PAUS block (no TZX ID)
+type PzxPause struct {
+	duration int
+	level    bool
+}
+
+// NewPzxPause creates a block from the content of a PZX PAUS block
+func NewPzxPause(data []byte) (*PzxPause, error) {
+	if len(data) < 4 {
+		return nil, errors.New("pzx pause: truncated block")
+	}
+	duration := binary.LittleEndian.Uint32(data[0:4])
+	return &PzxPause{
+		duration: int(duration & 0x7fffffff),
+		level:    duration&0x80000000 != 0,
+	}, nil
+}
+
+func (p *PzxPause) Id() byte {
+	return NoId
+}
+
+func (p *PzxPause) Name() string {
+	return "PZX Pause"
+}
+
+func (p *PzxPause) Read(tzxFile *os.File) error {
+	return errors.New("pzx pause: not a TZX block")
+}
+
+func (p *PzxPause) Info() [][]string {
+	return [][]string{
+		{"Pause duration", fmt.Sprintf("%d T-states", p.duration)},
+		{"Signal level", levelName(p.level)},
+	}
+}
+
+// Pulses returns the pause as a single pulse at the level of the pause
+func (p *PzxPause) Pulses() []Pulse {
+	if p.duration == 0 {
+		return make([]Pulse, 0)
+	}
+	return []Pulse{{Length: p.duration, Edge: levelEdge(p.level)}}
+}
+
+func (p *PzxPause) PauseDuration() int {
+	return 0
+}
+
+// PzxContent returns the content of the block in a PZX file
+func (p *PzxPause) PzxContent() (tag string, content []byte) {
+	duration := uint32(p.duration)
+	if p.level {
+		duration |= 0x80000000
+	}
+	return "PAUS", binary.LittleEndian.AppendUint32(nil, duration)
+}
+
+// EndsWithSilence tells the pause is a silence
+func (p *PzxPause) EndsWithSilence() bool {
+	return true
+}
+
+// levelEdge returns the edge forcing the given level
+func levelEdge(level bool) Edge {
+	if level {
+		return EdgeHigh
+	}
+	return EdgeLow
+}
+
+// levelName returns the name of the given level
+func levelName(level bool) string {
+	if level {
+		return "High"
+	}
+	return "Low"
+}
@@ -0,0 +1,92 @@
+package block
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"strconv"
+)
+
+// PzxPulseSequence - PZX PULS block (no TZX ID)
+type PzxPulseSequence struct {
+	pulsesLengths []int
+	content       []byte
+}
+
+// NewPzxPulseSequence creates a block from the content of a PZX PULS block
+func NewPzxPulseSequence(data []byte) (*PzxPulseSequence, error) {
+	p := &PzxPulseSequence{content: data}
+	truncated := errors.New("pzx pulse sequence: truncated block")
+
+	for pos := 0; pos < len(data); {
+		if pos+2 > len(data) {
+			return nil, truncated
+		}
+		count := 1
+		duration := int(binary.LittleEndian.Uint16(data[pos : pos+2]))
+		pos += 2
+		if duration > 0x8000 {
+			if pos+2 > len(data) {
+				return nil, truncated
+			}
+			count = duration & 0x7fff
+			duration = int(binary.LittleEndian.Uint16(data[pos : pos+2]))
+			pos += 2
+		}
+		if duration >= 0x8000 {
+			if pos+2 > len(data) {
+				return nil, truncated
+			}
+			duration = (duration&0x7fff)<<16 | int(binary.LittleEndian.Uint16(data[pos:pos+2]))
+			pos += 2
+		}
+		for i := 0; i < count; i++ {
+			p.pulsesLengths = append(p.pulsesLengths, duration)
+		}
+	}
+
+	return p, nil
+}
+
+func (p *PzxPulseSequence) Id() byte {
+	return NoId
+}
+
+func (p *PzxPulseSequence) Name() string {
+	return "PZX Pulse Sequence"
+}
+
+func (p *PzxPulseSequence) Read(tzxFile *os.File) error {
+	return errors.New("pzx pulse sequence: not a TZX block")
+}
+
+func (p *PzxPulseSequence) Info() [][]string {
+	return [][]string{
+		{"Pulses number", strconv.Itoa(len(p.pulsesLengths))},
+	}
+}
+
+// Pulses returns the pulses of the block. The first pulse is low, then the
+// level toggles at each pulse. A pulse of length 0 only toggles the level.
+func (p *PzxPulseSequence) Pulses() []Pulse {
+	pulses := make([]Pulse, 0, len(p.pulsesLengths))
+	for i, length := range p.pulsesLengths {
+		if len(pulses) > 0 {
+			pulses = append(pulses, Pulse{Length: length, Edge: EdgeToggle})
+		} else if length > 0 {
+			// The level of the first pulse is forced, the leading zero
+			// length pulses toggling it
+			pulses = append(pulses, Pulse{Length: length, Edge: levelEdge(i%2 == 1)})
+		}
+	}
+	return pulses
+}
+
+func (p *PzxPulseSequence) PauseDuration() int {
+	return 0
+}
+
+// PzxContent returns the content of the block in a PZX file
+func (p *PzxPulseSequence) PzxContent() (tag string, content []byte) {
+	return "PULS", p.content
+}
@@ -0,0 +1,31 @@
+package block
+
+import "os"
+
+// ReturnFromSequence - ID 27
+type ReturnFromSequence struct {
+}
+
+func (r *ReturnFromSequence) Id() byte {
+	return 0x27
+}
+
+func (r *ReturnFromSequence) Name() string {
+	return "Return from sequence"
+}
+
+func (r *ReturnFromSequence) Read(tzxFile *os.File) error {
+	return nil
+}
+
+func (r *ReturnFromSequence) Info() [][]string {
+	return [][]string{}
+}
+
+func (r *ReturnFromSequence) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (r *ReturnFromSequence) PauseDuration() int {
+	return 0
+}
@@ -0,0 +1,115 @@
+package block
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Select - ID 28
+type Select struct {
+	selections []Selection
+}
+
+// Selection is an entry of a Select block
+type Selection struct {
+	offset      int
+	description string
+}
+
+func (s *Select) Id() byte {
+	return 0x28
+}
+
+func (s *Select) Name() string {
+	return "Select block"
+}
+
+func (s *Select) Read(tzxFile *os.File) error {
+	var header struct {
+		BlockLength  uint16
+		SelectionsNb uint8
+	}
+	if err := binary.Read(tzxFile, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	// The block length counts the bytes following the length field
+	headerEnd, err := tzxFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	blockEnd := headerEnd - 1 + int64(header.BlockLength)
+
+	for i := 0; i < int(header.SelectionsNb); i++ {
+		var selection struct {
+			Offset            int16
+			DescriptionLength uint8
+		}
+		if err := binary.Read(tzxFile, binary.LittleEndian, &selection); err != nil {
+			return err
+		}
+		if selection.Offset == 0 {
+			return fmt.Errorf("select block: selection %d jumps to the select block itself", i+1)
+		}
+		description := make([]byte, selection.DescriptionLength)
+		if _, err := io.ReadFull(tzxFile, description); err != nil {
+			return err
+		}
+		s.selections = append(s.selections, Selection{
+			offset:      int(selection.Offset),
+			description: string(description),
+		})
+	}
+
+	// Skip the bytes left in the block, so the next blocks are read where
+	// they start even if the block content is shorter than its length
+	pos, err := tzxFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if pos > blockEnd {
+		return errors.New("select block: selections exceed the block length")
+	}
+	_, err = tzxFile.Seek(blockEnd, io.SeekStart)
+	return err
+}
+
+func (s *Select) Info() [][]string {
+	info := [][]string{
+		{"Number of selections", strconv.Itoa(len(s.selections))},
+	}
+	for i, selection := range s.selections {
+		info = append(info, []string{
+			fmt.Sprintf("Selection %d", i+1),
+			fmt.Sprintf("%s (relative offset: %d)", selection.description, selection.offset),
+		})
+	}
+	return info
+}
+
+func (s *Select) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (s *Select) PauseDuration() int {
+	return 0
+}
+
+// Selections returns the entries of this select block
+func (s *Select) Selections() []Selection {
+	return s.selections
+}
+
+// Offset returns the offset, relative to the select block, of the block to
+// jump to when this selection is chosen
+func (s Selection) Offset() int {
+	return s.offset
+}
+
+// Description returns the description text of this selection
+func (s Selection) Description() string {
+	return s.description
+}
@@ -0,0 +1,66 @@
+package block
+
+import (
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// selectBlock returns the content of a Select block following its ID, with
+// the given selections and padding bytes counted in the block length
+func selectBlock(padding int, selections ...Selection) []byte {
+	content := []byte{byte(len(selections))}
+	for _, selection := range selections {
+		content = binary.LittleEndian.AppendUint16(content, uint16(int16(selection.offset)))
+		content = append(content, byte(len(selection.description)))
+		content = append(content, selection.description...)
+	}
+	length := binary.LittleEndian.AppendUint16(nil, uint16(len(content)+padding))
+	return append(length, content...)
+}
+
+func TestSelectRead(t *testing.T) {
+	selections := []Selection{{1, "Side A"}, {-2, "Side B"}}
+	tests := []struct {
+		name       string
+		content    []byte
+		selections []Selection
+		err        string
+	}{
+		{"selections", selectBlock(0, selections...), selections, ""},
+		{"bytes left in the block", append(selectBlock(2, selections...), 0, 0), selections, ""},
+		{"zero offset", selectBlock(0, Selection{1, "Side A"}, Selection{0, "Side B"}), nil, "selection 2 jumps to the select block itself"},
+		{"truncated description", selectBlock(0, selections...)[:10], nil, io.ErrUnexpectedEOF.Error()},
+		{"selections exceeding the block length", selectBlock(-1, selections...), nil, "selections exceed the block length"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := testFile(t, test.content)
+			s := &Select{}
+			err := s.Read(f)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pos, err := f.Seek(0, io.SeekCurrent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pos != int64(len(test.content)) {
+				t.Errorf("block read up to offset %d, expected %d", pos, len(test.content))
+			}
+			if !reflect.DeepEqual(s.Selections(), test.selections) {
+				t.Errorf("selections %v, expected %v", s.Selections(), test.selections)
+			}
+		})
+	}
+}
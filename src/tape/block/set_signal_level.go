@@ -0,0 +1,54 @@
+package block
+
+import (
+	"encoding/binary"
+	"os"
+)
+
+// SetSignalLevel - ID 2B
+type SetSignalLevel struct {
+	level bool
+}
+
+func (s *SetSignalLevel) Id() byte {
+	return 0x2B
+}
+
+func (s *SetSignalLevel) Name() string {
+	return "Set signal level"
+}
+
+func (s *SetSignalLevel) Read(tzxFile *os.File) error {
+	var data struct {
+		BlockLength uint32
+		Level       uint8
+	}
+	if err := binary.Read(tzxFile, binary.LittleEndian, &data); err != nil {
+		return err
+	}
+	s.level = data.Level == 1
+	return nil
+}
+
+func (s *SetSignalLevel) Info() [][]string {
+	level := "Low"
+	if s.level {
+		level = "High"
+	}
+	return [][]string{
+		{"Signal level", level},
+	}
+}
+
+// Pulses returns a zero length pulse which forces the signal level
+func (s *SetSignalLevel) Pulses() []Pulse {
+	edge := EdgeLow
+	if s.level {
+		edge = EdgeHigh
+	}
+	return []Pulse{{Length: 0, Edge: edge}}
+}
+
+func (s *SetSignalLevel) PauseDuration() int {
+	return 0
+}
@@ -0,0 +1,69 @@
+package block
+
+import (
+	"encoding/binary"
+	"os"
+	"strconv"
+)
+
+var SnapshotTypes map[byte]string
+
+func init() {
+	SnapshotTypes = map[byte]string{
+		0x00: ".Z80",
+		0x01: ".SNA",
+	}
+}
+
+// Snapshot - ID 40
+// Deprecated since TZX 1.20
+type Snapshot struct {
+	snapshotType byte
+	data         []byte
+}
+
+func (s *Snapshot) Id() byte {
+	return 0x40
+}
+
+func (s *Snapshot) Name() string {
+	return "Snapshot"
+}
+
+func (s *Snapshot) Read(tzxFile *os.File) error {
+	var header struct {
+		SnapshotType uint8
+		DataSize     [3]byte
+	}
+	if err := binary.Read(tzxFile, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	s.snapshotType = header.SnapshotType
+
+	data := make([]byte, binary.LittleEndian.Uint32(append(header.DataSize[:], 0)))
+	if _, err := tzxFile.Read(data); err != nil {
+		return err
+	}
+	s.data = data
+
+	return nil
+}
+
+func (s *Snapshot) Info() [][]string {
+	snapshotType, ok := SnapshotTypes[s.snapshotType]
+	if !ok {
+		snapshotType = "Unknown"
+	}
+	return [][]string{
+		{"Snapshot type", snapshotType},
+		{"Snapshot length", strconv.Itoa(len(s.data))},
+	}
+}
+
+func (s *Snapshot) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (s *Snapshot) PauseDuration() int {
+	return 0
+}
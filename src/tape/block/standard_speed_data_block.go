@@ -23,6 +23,20 @@ type StandardSpeedDataBlock struct {
 	data            []byte
 }
 
+// NewStandardSpeedDataBlock creates a block from its data, including the flag
+// and checksum bytes, and the pause in ms after the block
+func NewStandardSpeedDataBlock(data []byte, pauseAfterBlock int) *StandardSpeedDataBlock {
+	s := &StandardSpeedDataBlock{
+		pauseAfterBlock: pauseAfterBlock,
+		dataSize:        len(data),
+		data:            data,
+	}
+	if len(data) > 0 {
+		s.dataFlag = data[0]
+	}
+	return s
+}
+
 func (s *StandardSpeedDataBlock) Id() byte {
 	return 0x10
 }
@@ -50,7 +64,9 @@ func (s *StandardSpeedDataBlock) Read(tzxFile *os.File) error {
 	}
 	s.data = data
 
-	s.dataFlag = data[0]
+	if len(data) > 0 {
+		s.dataFlag = data[0]
+	}
 
 	return nil
 }
@@ -65,28 +81,16 @@ func (s *StandardSpeedDataBlock) Info() [][]string {
 
 func (s *StandardSpeedDataBlock) Pulses() []Pulse {
 	pulses := make([]Pulse, 0)
-	level := false
 
 	// Generate pilot tone
-	pilotToneLength := StandardHeaderPilotToneLength
-	if s.data[0] >= 128 {
-		pilotToneLength = StandardDataPilotToneLength
-	}
-	for i := 0; i < pilotToneLength; i++ {
-		pulses = append(pulses, Pulse{Length: StandardPilotPulseLength, Level: level})
-		level = !level
+	for i := 0; i < s.PilotPulsesNb(); i++ {
+		pulses = append(pulses, Pulse{Length: StandardPilotPulseLength})
 	}
 
 	// Generate sync pulses
 	pulses = append(pulses, []Pulse{
-		{
-			Length: StandardFirstSyncPulseLength,
-			Level:  level,
-		},
-		{
-			Length: StandardSecondSyncPulseLength,
-			Level:  !level,
-		},
+		{Length: StandardFirstSyncPulseLength},
+		{Length: StandardSecondSyncPulseLength},
 	}...)
 
 	// Generate data pulses
@@ -97,35 +101,47 @@ func (s *StandardSpeedDataBlock) Pulses() []Pulse {
 				pulseLength = StandardOneBitPulseLength
 			}
 			pulses = append(pulses, []Pulse{
-				{
-					Length: pulseLength,
-					Level:  level,
-				},
-				{
-					Length: pulseLength,
-					Level:  !level,
-				},
+				{Length: pulseLength},
+				{Length: pulseLength},
 			}...)
 		}
 	}
 
-	// Generate trailer
-	for i := 0; i < 32; i++ {
-		pulses = append(pulses, []Pulse{
-			{
-				Length: StandardOneBitPulseLength,
-				Level:  level,
-			},
-			{
-				Length: StandardOneBitPulseLength,
-				Level:  !level,
-			},
-		}...)
-	}
-
 	return pulses
 }
 
 func (s *StandardSpeedDataBlock) PauseDuration() int {
 	return s.pauseAfterBlock
 }
+
+// PilotPulsesNb returns the length of the pilot tone, which depends on the
+// kind of data: header or data
+func (s *StandardSpeedDataBlock) PilotPulsesNb() int {
+	// A block without data is not a header
+	if len(s.data) == 0 || s.data[0] >= 128 {
+		return StandardDataPilotToneLength
+	}
+	return StandardHeaderPilotToneLength
+}
+
+// DataBits returns the data of the block, all its bits being used
+func (s *StandardSpeedDataBlock) DataBits() ([]byte, int, int, int) {
+	return s.data, len(s.data) * 8, StandardZeroBitPulseLength, StandardOneBitPulseLength
+}
+
+// TailPulses returns 32 ONE bits
+func (s *StandardSpeedDataBlock) TailPulses() []Pulse {
+	return tailPulses(StandardOneBitPulseLength)
+}
+
+// tailPulses returns the pulses of the compatibility tail: 32 ONE bits
+func tailPulses(oneBitPulseLength int) []Pulse {
+	pulses := make([]Pulse, 0)
+	for i := 0; i < 32; i++ {
+		pulses = append(pulses, []Pulse{
+			{Length: oneBitPulseLength},
+			{Length: oneBitPulseLength},
+		}...)
+	}
+	return pulses
+}
@@ -0,0 +1,39 @@
+package block
+
+import (
+	"encoding/binary"
+	"os"
+)
+
+// StopTape48K - ID 2A
+type StopTape48K struct {
+}
+
+func (s *StopTape48K) Id() byte {
+	return 0x2A
+}
+
+func (s *StopTape48K) Name() string {
+	return "Stop the tape if in 48K mode"
+}
+
+func (s *StopTape48K) Read(tzxFile *os.File) error {
+	var blockLength uint32
+	if err := binary.Read(tzxFile, binary.LittleEndian, &blockLength); err != nil {
+		return err
+	}
+	_, err := tzxFile.Seek(int64(blockLength), 1)
+	return err
+}
+
+func (s *StopTape48K) Info() [][]string {
+	return [][]string{}
+}
+
+func (s *StopTape48K) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (s *StopTape48K) PauseDuration() int {
+	return 0
+}
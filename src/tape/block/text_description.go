@@ -9,6 +9,11 @@ type TextDescription struct {
 	description string
 }
 
+// NewTextDescription creates a block from its text
+func NewTextDescription(description string) *TextDescription {
+	return &TextDescription{description: description}
+}
+
 func (t *TextDescription) Id() byte {
 	return 0x30
 }
@@ -43,3 +48,8 @@ func (t *TextDescription) Pulses() []Pulse {
 func (t *TextDescription) PauseDuration() int {
 	return 0
 }
+
+// Description returns the text of the block
+func (t *TextDescription) Description() string {
+	return t.description
+}
@@ -91,7 +91,9 @@ func (t *TurboSpeedDataBlock) Read(tzxFile *os.File) error {
 	}
 	t.data = data
 
-	t.dataFlag = data[0]
+	if len(data) > 0 {
+		t.dataFlag = data[0]
+	}
 
 	return nil
 }
@@ -113,64 +115,50 @@ func (t *TurboSpeedDataBlock) Info() [][]string {
 
 func (t *TurboSpeedDataBlock) Pulses() []Pulse {
 	pulses := make([]Pulse, 0)
-	level := false
 
 	// Generate pilot tone
 	for i := 0; i < t.pilotToneLength; i++ {
-		pulses = append(pulses, Pulse{Length: t.pilotPulseLength, Level: level})
-		level = !level
+		pulses = append(pulses, Pulse{Length: t.pilotPulseLength})
 	}
 
 	// Generate sync pulses
 	pulses = append(pulses, []Pulse{
-		{
-			Length: t.syncFirstPulseLength,
-			Level:  level,
-		},
-		{
-			Length: t.syncSecondPulseLength,
-			Level:  !level,
-		},
+		{Length: t.syncFirstPulseLength},
+		{Length: t.syncSecondPulseLength},
 	}...)
 
 	// Generate data pulses
-	for _, dataByte := range t.data {
-		// @TODO: don't convert unused bits in last byte
-		for i := 128; i >= 1; i = i / 2 { // Iterate over every bit
+	for j, dataByte := range t.data {
+		for i := 128; i >= lastBit(j, len(t.data), t.lastByteBitsUsed); i = i / 2 { // Iterate over every used bit
 			pulseLength := t.zeroBitPulseLength
 			if int(dataByte)&i > 0 {
 				pulseLength = t.oneBitPulseLength
 			}
 			pulses = append(pulses, []Pulse{
-				{
-					Length: pulseLength,
-					Level:  level,
-				},
-				{
-					Length: pulseLength,
-					Level:  !level,
-				},
+				{Length: pulseLength},
+				{Length: pulseLength},
 			}...)
 		}
 	}
 
-	// Generate trailer
-	for i := 0; i < 32; i++ {
-		pulses = append(pulses, []Pulse{
-			{
-				Length: t.oneBitPulseLength,
-				Level:  level,
-			},
-			{
-				Length: t.oneBitPulseLength,
-				Level:  !level,
-			},
-		}...)
-	}
-
 	return pulses
 }
 
 func (t *TurboSpeedDataBlock) PauseDuration() int {
 	return t.pauseAfterBlock
 }
+
+// PilotPulsesNb returns the length of the pilot tone
+func (t *TurboSpeedDataBlock) PilotPulsesNb() int {
+	return t.pilotToneLength
+}
+
+// DataBits returns the data of the block and the number of used bits
+func (t *TurboSpeedDataBlock) DataBits() ([]byte, int, int, int) {
+	return t.data, usedBitsNb(len(t.data), t.lastByteBitsUsed), t.zeroBitPulseLength, t.oneBitPulseLength
+}
+
+// TailPulses returns 32 ONE bits
+func (t *TurboSpeedDataBlock) TailPulses() []Pulse {
+	return tailPulses(t.oneBitPulseLength)
+}
@@ -0,0 +1,326 @@
+package block
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// UEF chunks ids
+const UefOriginInformation = 0x0000
+const UefImplicitDataBlock = 0x0100
+const UefExplicitDataBlock = 0x0102
+const UefDefinedFormatDataBlock = 0x0104
+const UefCarrierTone = 0x0110
+const UefCarrierToneWithDummyByte = 0x0111
+const UefIntegerGap = 0x0112
+const UefBaseFrequencyChange = 0x0113
+const UefSecurityCycles = 0x0114
+const UefPhaseChange = 0x0115
+const UefFloatingPointGap = 0x0116
+const UefDataEncodingChange = 0x0117
+const UefPositionMarker = 0x0120
+
+// UefDefaultBaseFrequency is the frequency in Hz of the cycles of the bits 0,
+// the bits 1 being encoded by cycles of twice this frequency
+const UefDefaultBaseFrequency = 1200
+
+// UefDefaultBaudRate is the number of bits per second of the data
+const UefDefaultBaudRate = 1200
+
+var UefChunkNames map[uint16]string
+
+func init() {
+	UefChunkNames = map[uint16]string{
+		0x0000:                      "Origin information",
+		0x0001:                      "Game instructions/manual",
+		0x0003:                      "Inlay scan",
+		0x0005:                      "Target machine",
+		0x0006:                      "Bit multiplexing information",
+		0x0007:                      "Extra palette",
+		0x0008:                      "ROM hint",
+		0x0009:                      "Short title",
+		0x000A:                      "Visible area",
+		UefImplicitDataBlock:        "Implicit start/stop bit tape data block",
+		0x0101:                      "Multiplexed data block",
+		UefExplicitDataBlock:        "Explicit tape data block",
+		UefDefinedFormatDataBlock:   "Defined tape format data block",
+		UefCarrierTone:              "Carrier tone",
+		UefCarrierToneWithDummyByte: "Carrier tone with dummy byte",
+		UefIntegerGap:               "Integer gap",
+		UefBaseFrequencyChange:      "Change of base frequency",
+		UefSecurityCycles:           "Security cycles",
+		UefPhaseChange:              "Phase change",
+		UefFloatingPointGap:         "Floating point gap",
+		UefDataEncodingChange:       "Data encoding format change",
+		UefPositionMarker:           "Position marker",
+		0x0130:                      "Tape set info",
+		0x0131:                      "Start of tape side",
+	}
+}
+
+// UefEncoding is the encoding of the UEF tape chunks, which is changed by
+// some chunks and applies to the following ones
+type UefEncoding struct {
+	// BaseFrequency is the frequency in Hz of the cycles of the bits 0
+	BaseFrequency float64
+
+	// BaudRate is the number of bits per second of the data: 1200 or 300
+	BaudRate int
+}
+
+// UefChunk - UEF tape chunk (no TZX ID)
+type UefChunk struct {
+	chunkId uint16
+	info    [][]string
+	pulses  []Pulse
+	gap     bool
+
+	// position is the end of the pulses in T-states, not rounded
+	position float64
+}
+
+// NewUefChunk creates a block from a chunk of an UEF file. The pulses are
+// generated from the given encoding, which is updated by the chunks changing
+// it.
+func NewUefChunk(chunkId uint16, data []byte, encoding *UefEncoding) (*UefChunk, error) {
+	c := &UefChunk{chunkId: chunkId}
+	c.info = [][]string{{"Chunk ID", fmt.Sprintf("%04x", chunkId)}}
+	truncated := fmt.Errorf("uef chunk %04x: truncated chunk", chunkId)
+
+	switch chunkId {
+	case UefImplicitDataBlock:
+		for _, dataByte := range data {
+			c.appendByte(dataByte, 8, 'N', 1, encoding)
+		}
+		c.info = append(c.info, []string{"Bytes number", strconv.Itoa(len(data))}, baudRateInfo(encoding))
+
+	case UefExplicitDataBlock:
+		if len(data) < 1 {
+			return nil, truncated
+		}
+		bitsNb := len(data)*8 - int(data[0])
+		if bitsNb > (len(data)-1)*8 {
+			bitsNb = (len(data) - 1) * 8
+		}
+		for i := 0; i < bitsNb; i++ {
+			c.appendBit(data[1+i/8]&(1<<(i%8)) != 0, encoding)
+		}
+		c.info = append(c.info, []string{"Bits number", strconv.Itoa(bitsNb)}, baudRateInfo(encoding))
+
+	case UefDefinedFormatDataBlock:
+		if len(data) < 3 {
+			return nil, truncated
+		}
+		bitsPerPacket := int(data[0])
+		parity := data[1]
+		stopBits := int(int8(data[2]))
+		for _, dataByte := range data[3:] {
+			c.appendByte(dataByte, bitsPerPacket, parity, stopBits, encoding)
+		}
+		c.info = append(c.info,
+			[]string{"Bytes number", strconv.Itoa(len(data) - 3)},
+			[]string{"Packet format", fmt.Sprintf("%d%c%d", bitsPerPacket, parity, stopBits)},
+			baudRateInfo(encoding),
+		)
+
+	case UefCarrierTone:
+		if len(data) < 2 {
+			return nil, truncated
+		}
+		cycles := int(binary.LittleEndian.Uint16(data[0:2]))
+		c.appendCycles(cycles, 2, encoding)
+		c.info = append(c.info, []string{"Cycles number", strconv.Itoa(cycles)})
+
+	case UefCarrierToneWithDummyByte:
+		if len(data) < 4 {
+			return nil, truncated
+		}
+		before := int(binary.LittleEndian.Uint16(data[0:2]))
+		after := int(binary.LittleEndian.Uint16(data[2:4]))
+		c.appendCycles(before, 2, encoding)
+		c.appendByte(0xAA, 8, 'N', 1, encoding)
+		c.appendCycles(after, 2, encoding)
+		c.info = append(c.info,
+			[]string{"Cycles number before dummy byte", strconv.Itoa(before)},
+			[]string{"Cycles number after dummy byte", strconv.Itoa(after)},
+		)
+
+	case UefIntegerGap:
+		if len(data) < 2 {
+			return nil, truncated
+		}
+		c.appendGap(float64(binary.LittleEndian.Uint16(data[0:2])) / (2 * encoding.BaseFrequency))
+
+	case UefFloatingPointGap:
+		if len(data) < 4 {
+			return nil, truncated
+		}
+		c.appendGap(float64(uefFloat(data[0:4])))
+
+	case UefBaseFrequencyChange:
+		if len(data) < 4 {
+			return nil, truncated
+		}
+		frequency := float64(uefFloat(data[0:4]))
+		if frequency <= 0 {
+			return nil, fmt.Errorf("uef chunk %04x: invalid base frequency", chunkId)
+		}
+		encoding.BaseFrequency = frequency
+		c.info = append(c.info, []string{"Base frequency", fmt.Sprintf("%.1f Hz", frequency)})
+
+	case UefDataEncodingChange:
+		if len(data) < 2 {
+			return nil, truncated
+		}
+		encoding.BaudRate = int(binary.LittleEndian.Uint16(data[0:2]))
+		c.info = append(c.info, baudRateInfo(encoding))
+
+	case UefSecurityCycles:
+		if len(data) < 5 {
+			return nil, truncated
+		}
+		cycles := int(data[0]) | int(data[1])<<8 | int(data[2])<<16
+		if 5+(cycles+7)/8 > len(data) {
+			return nil, truncated
+		}
+		for i := 0; i < cycles; i++ {
+			multiplier := 1
+			if data[5+i/8]&(0x80>>(i%8)) != 0 {
+				multiplier = 2
+			}
+			halfCycle := 1 / (2 * encoding.BaseFrequency * float64(multiplier))
+			// A 'P' first or last cycle is only a pulse: the high half of the
+			// first cycle, the low half of the last one
+			if i > 0 || data[3] != 'P' {
+				c.appendPulse(EdgeLow, halfCycle)
+			}
+			if i < cycles-1 || data[4] != 'P' {
+				c.appendPulse(EdgeHigh, halfCycle)
+			}
+		}
+		c.info = append(c.info, []string{"Cycles number", strconv.Itoa(cycles)})
+
+	case UefPhaseChange:
+		if len(data) < 2 {
+			return nil, truncated
+		}
+		c.info = append(c.info, []string{"Phase", fmt.Sprintf("%d degrees", binary.LittleEndian.Uint16(data[0:2]))})
+	}
+
+	return c, nil
+}
+
+func (c *UefChunk) Id() byte {
+	return NoId
+}
+
+func (c *UefChunk) Name() string {
+	if name, ok := UefChunkNames[c.chunkId]; ok {
+		return "UEF " + name
+	}
+	return "UEF Unknown chunk"
+}
+
+func (c *UefChunk) Read(tzxFile *os.File) error {
+	return errors.New("uef chunk: not a TZX block")
+}
+
+func (c *UefChunk) Info() [][]string {
+	return c.info
+}
+
+func (c *UefChunk) Pulses() []Pulse {
+	return c.pulses
+}
+
+func (c *UefChunk) PauseDuration() int {
+	return 0
+}
+
+// EndsWithSilence tells if the chunk is a gap
+func (c *UefChunk) EndsWithSilence() bool {
+	return c.gap
+}
+
+// appendByte appends the pulses of a byte: a start bit, the data bits from the
+// least significant one, an optional parity bit and the stop bits. A negative
+// number of stop bits adds an extra short cycle after them.
+func (c *UefChunk) appendByte(dataByte byte, bitsNb int, parity byte, stopBits int, encoding *UefEncoding) {
+	c.appendBit(false, encoding)
+	ones := 0
+	for i := 0; i < bitsNb; i++ {
+		bit := dataByte&(1<<i) != 0
+		if bit {
+			ones++
+		}
+		c.appendBit(bit, encoding)
+	}
+	switch parity {
+	case 'E':
+		c.appendBit(ones%2 == 1, encoding)
+	case 'O':
+		c.appendBit(ones%2 == 0, encoding)
+	}
+	for i := 0; i < stopBits || i < -stopBits; i++ {
+		c.appendBit(true, encoding)
+	}
+	if stopBits < 0 {
+		c.appendCycles(1, 2, encoding)
+	}
+}
+
+// appendBit appends the cycles of a bit: a cycle at the base frequency for a
+// 0, two cycles at twice this frequency for a 1. At 300 bauds, there are four
+// times more cycles.
+func (c *UefChunk) appendBit(bit bool, encoding *UefEncoding) {
+	cycles := 1
+	if encoding.BaudRate == 300 {
+		cycles = 4
+	}
+	if bit {
+		c.appendCycles(cycles*2, 2, encoding)
+	} else {
+		c.appendCycles(cycles, 1, encoding)
+	}
+}
+
+// appendCycles appends cycles at the base frequency multiplied by the given
+// multiplier. A cycle is a low pulse followed by a high pulse.
+func (c *UefChunk) appendCycles(cycles int, multiplier int, encoding *UefEncoding) {
+	halfCycle := 1 / (2 * encoding.BaseFrequency * float64(multiplier))
+	for i := 0; i < cycles; i++ {
+		c.appendPulse(EdgeLow, halfCycle)
+		c.appendPulse(EdgeHigh, halfCycle)
+	}
+}
+
+// appendGap appends a silence of the given duration in seconds
+func (c *UefChunk) appendGap(duration float64) {
+	c.appendPulse(EdgeLow, duration)
+	c.gap = true
+	c.info = append(c.info, []string{"Gap duration", fmt.Sprintf("%.3f s", duration)})
+}
+
+// appendPulse appends a pulse of the given duration in seconds. The pulses
+// lengths are rounded from the position of their end, so the rounding errors
+// don't add up.
+func (c *UefChunk) appendPulse(edge Edge, duration float64) {
+	start := math.Round(c.position)
+	c.position += duration * ZXClockHz
+	c.pulses = append(c.pulses, Pulse{Length: int(math.Round(c.position) - start), Edge: edge})
+}
+
+// uefFloat decodes a 4 bytes UEF float, which is an IEEE 754 single precision
+// float stored little endian
+func uefFloat(data []byte) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(data))
+}
+
+// baudRateInfo returns the information parameter of the baud rate
+func baudRateInfo(encoding *UefEncoding) []string {
+	return []string{"Baud rate", strconv.Itoa(encoding.BaudRate)}
+}
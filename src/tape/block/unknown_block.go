@@ -0,0 +1,64 @@
+package block
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// UnknownBlock holds a block of a type not supported by the player.
+// As stated by the TZX specification since version 1.10, the block data
+// begins with its length, which allows to skip it. The block renders
+// nothing: the playback goes on with the next block.
+type UnknownBlock struct {
+	id   byte
+	data []byte
+}
+
+func (u *UnknownBlock) Id() byte {
+	return u.id
+}
+
+func (u *UnknownBlock) Name() string {
+	return "Unknown block"
+}
+
+func (u *UnknownBlock) Read(tzxFile *os.File) error {
+	var blockLength uint32
+	if err := binary.Read(tzxFile, binary.LittleEndian, &blockLength); err != nil {
+		return err
+	}
+
+	if err := checkRemainingSize(tzxFile, int64(blockLength)); err != nil {
+		return fmt.Errorf("unknown block %x: %s", u.id, err.Error())
+	}
+	data := make([]byte, blockLength)
+	if _, err := io.ReadFull(tzxFile, data); err != nil {
+		return err
+	}
+	u.data = data
+
+	return nil
+}
+
+func (u *UnknownBlock) Info() [][]string {
+	return [][]string{
+		{"Status", "Unsupported block type, skipped: nothing is played"},
+		{"Data length", strconv.Itoa(len(u.data))},
+	}
+}
+
+func (u *UnknownBlock) Pulses() []Pulse {
+	return make([]Pulse, 0)
+}
+
+func (u *UnknownBlock) PauseDuration() int {
+	return 0
+}
+
+// Data returns the raw data of the block, without the length
+func (u *UnknownBlock) Data() []byte {
+	return u.data
+}
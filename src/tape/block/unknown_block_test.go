@@ -0,0 +1,51 @@
+package block
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnknownBlockRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		dataLen int
+		err     string
+	}{
+		{"empty", []byte{0, 0, 0, 0}, 0, ""},
+		{"data", []byte{3, 0, 0, 0, 1, 2, 3, 0x20}, 3, ""},
+		{"truncated", []byte{4, 0, 0, 0, 1, 2, 3}, 0, "unknown block 5f: block length 4 exceeds the 3 bytes left in the file"},
+		{"huge length", []byte{0xff, 0xff, 0xff, 0xff, 1}, 0, "exceeds the 1 bytes left in the file"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := NewBlock(0x5F, testFile(t, test.content))
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(b.(*UnknownBlock).Data()); got != test.dataLen {
+				t.Errorf("%d bytes of data, expected %d", got, test.dataLen)
+			}
+		})
+	}
+}
+
+func TestUnknownBlockRendersNothing(t *testing.T) {
+	b, err := NewBlock(0x5F, testFile(t, []byte{3, 0, 0, 0, 1, 2, 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Pulses()) != 0 || b.PauseDuration() != 0 {
+		t.Errorf("%d pulses and a %d ms pause, expected nothing", len(b.Pulses()), b.PauseDuration())
+	}
+	if status := b.Info()[0][1]; !strings.Contains(status, "nothing is played") {
+		t.Errorf("status %q does not tell nothing is played", status)
+	}
+}
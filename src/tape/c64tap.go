@@ -0,0 +1,69 @@
+package tape
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/TiBeN/tzx-player/tape/block"
+)
+
+const C64TapSignature = "C64-TAPE-RAW"
+
+// VideoStandard is the video standard of the Commodore computer a C64 TAP
+// file is played to, which gives the clock of its pulses
+type VideoStandard int
+
+const (
+	// VideoStandardFile uses the video standard given by the file header
+	VideoStandardFile VideoStandard = iota
+	VideoStandardPal
+	VideoStandardNtsc
+)
+
+// readC64Tap reads the content of a Commodore TAP file (versions 0, 1 and 2)
+// as a single C64 Tape Recording block
+func (t *Tape) readC64Tap(content []byte) error {
+	if len(content) < 20 {
+		return errors.New("not a valid C64 TAP file (truncated header)")
+	}
+	t.Header = Header{
+		MajorVersion: int(content[12]),
+	}
+
+	dataSize := int(binary.LittleEndian.Uint32(content[16:20]))
+	data := content[20:]
+	if dataSize > len(data) {
+		return errors.New("not a valid C64 TAP file (truncated data)")
+	}
+	data = data[:dataSize]
+
+	b, err := block.NewC64TapeRecording(content[12], content[13], content[14], data)
+	if err != nil {
+		return err
+	}
+	t.Blocks = []block.Block{b}
+
+	return nil
+}
+
+// withVideoStandard returns the tape with its C64 Tape Recording blocks played
+// with the given video standard. The tape is copied so it is not changed, the
+// blocks which are not C64 Tape Recording blocks are shared.
+func (t *Tape) withVideoStandard(videoStandard VideoStandard) *Tape {
+	if videoStandard == VideoStandardFile {
+		return t
+	}
+	c64VideoStandard := byte(block.C64VideoPal)
+	if videoStandard == VideoStandardNtsc {
+		c64VideoStandard = block.C64VideoNtsc
+	}
+
+	tape := *t
+	tape.Blocks = make([]block.Block, len(t.Blocks))
+	for i, b := range t.Blocks {
+		if c, ok := b.(*block.C64TapeRecording); ok {
+			b = c.WithVideoStandard(c64VideoStandard)
+		}
+		tape.Blocks[i] = b
+	}
+	return &tape
+}
@@ -0,0 +1,105 @@
+package tape
+
+import (
+	"encoding/binary"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"reflect"
+	"testing"
+)
+
+// c64Tap returns the content of a C64 TAP file of the given version, for a C64
+// of the given video standard
+func c64Tap(version byte, videoStandard byte, data []byte) []byte {
+	content := append([]byte(C64TapSignature), version, 0, videoStandard, 0)
+	content = binary.LittleEndian.AppendUint32(content, uint32(len(data)))
+	return append(content, data...)
+}
+
+func TestReadC64Tap(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		pulsesNb int
+		err      string
+	}{
+		{"v0", c64Tap(0, 0, []byte{0x30, 0x00}), 4, ""},
+		{"v1 long pulse", c64Tap(1, 0, []byte{0x30, 0x00, 0x10, 0x27, 0x00}), 4, ""},
+		{"v2 half waves", c64Tap(2, 0, []byte{0x30, 0x40, 0x30}), 3, ""},
+		{"data after the data size", append(c64Tap(1, 0, []byte{0x30}), 0x40), 2, ""},
+		{"truncated header", c64Tap(1, 0, nil)[:15], 0, "not a valid C64 TAP file (truncated header)"},
+		{"truncated data", c64Tap(1, 0, []byte{0x30, 0x40})[:21], 0, "not a valid C64 TAP file (truncated data)"},
+		{"unsupported version", c64Tap(3, 0, []byte{0x30}), 0, "c64 tape recording: unsupported version 3"},
+		{"truncated long pulse", c64Tap(1, 0, []byte{0x30, 0x00, 0x10}), 0, "c64 tape recording: truncated long pulse"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tape, ok := readTestTape(t, "test.tap", test.content, test.err)
+			if !ok {
+				return
+			}
+			if tape.Format != FormatC64Tap {
+				t.Errorf("format %s", tape.Format)
+			}
+			if names := blockNames(tape); !reflect.DeepEqual(names, []string{"C64 Tape Recording"}) {
+				t.Fatalf("blocks %v", names)
+			}
+			if pulsesNb := len(tape.Blocks[0].Pulses()); pulsesNb != test.pulsesNb {
+				t.Errorf("%d pulses, expected %d", pulsesNb, test.pulsesNb)
+			}
+		})
+	}
+}
+
+func TestVideoStandardOption(t *testing.T) {
+	tape := newTestTape(t, "test.tap", c64Tap(1, 0, []byte{0x30, 0x30, 0x40}))
+	palPulses := tape.Blocks[0].Pulses()
+
+	r, err := NewReader(tape, ReaderOptions{SamplingRate: 44100, BitDepth: 8, SpeedFactor: 1, VideoStandard: VideoStandardNtsc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ntscPulses := r.tape.Blocks[0].Pulses()
+	if ntscPulses[0].Length == palPulses[0].Length {
+		t.Errorf("same pulse length %d with NTSC and PAL", ntscPulses[0].Length)
+	}
+
+	// The tape is shared and not changed by the option
+	for i, pulse := range tape.Blocks[0].Pulses() {
+		if pulse != palPulses[i] {
+			t.Fatalf("pulse %d changed from %v to %v", i+1, palPulses[i], pulse)
+		}
+	}
+	if _, err := writePzx(tape, ReaderOptions{VideoStandard: VideoStandardNtsc}); err != nil {
+		t.Fatal(err)
+	}
+	if pulses := tape.Blocks[0].Pulses(); pulses[0] != palPulses[0] {
+		t.Errorf("pulse changed from %v to %v", palPulses[0], pulses[0])
+	}
+}
+
+func TestInfoBlockId(t *testing.T) {
+	tests := []struct {
+		name    string
+		tape    *Tape
+		blockId bool
+	}{
+		{"TZX block", newTestTape(t, "test.tzx", tzx(tzxPureTone(1000, 2))), true},
+		{"C64 TAP recording", newTestTape(t, "test.tap", c64Tap(1, 0, []byte{0x30})), false},
+		{"UEF chunk", newTestTape(t, "test.uef", uef(uefChunk(block.UefCarrierTone, []byte{3, 0}))), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blockId := false
+			for _, param := range test.tape.Info().Blocks[0] {
+				if param[0] == "Block ID" {
+					blockId = true
+				}
+			}
+			if blockId != test.blockId {
+				t.Errorf("block ID shown: %v, expected %v", blockId, test.blockId)
+			}
+		})
+	}
+}
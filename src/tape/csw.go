@@ -0,0 +1,67 @@
+package tape
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"io"
+	"os"
+)
+
+const CswSignature = "Compressed Square Wave\x1a"
+
+// readCsw reads a CSW v1 or v2 file as a single CSW Recording block
+func (t *Tape) readCsw(cswFile *os.File) error {
+	content, err := io.ReadAll(cswFile)
+	if err != nil {
+		return err
+	}
+
+	if len(content) < 32 || string(content[0:23]) != CswSignature {
+		return errors.New("not a valid CSW file (no CSW signature in header)")
+	}
+	t.Header = Header{
+		MajorVersion: int(content[23]),
+		MinorVersion: int(content[24]),
+	}
+
+	var samplingRate int
+	var compressionType byte
+	var flags byte
+	var dataStart int
+	pulsesNb := -1
+	switch t.Header.MajorVersion {
+	case 1:
+		samplingRate = int(binary.LittleEndian.Uint16(content[25:27]))
+		compressionType = content[27]
+		flags = content[28]
+		dataStart = 32
+	case 2:
+		if len(content) < 52 {
+			return errors.New("not a valid CSW file (truncated header)")
+		}
+		samplingRate = int(binary.LittleEndian.Uint32(content[25:29]))
+		pulsesNb = int(binary.LittleEndian.Uint32(content[29:33]))
+		compressionType = content[33]
+		flags = content[34]
+		dataStart = 52 + int(content[35])
+		if dataStart > len(content) {
+			return errors.New("not a valid CSW file (truncated header extension)")
+		}
+	default:
+		return errors.New("not a valid CSW file (unsupported version)")
+	}
+
+	b, err := block.NewCSWRecording(samplingRate, compressionType, content[dataStart:], flags&0x01 == 1)
+	if err != nil {
+		return err
+	}
+	// Only CSW v2 files store the number of pulses
+	if pulsesNb >= 0 && b.PulsesNb() != pulsesNb {
+		return fmt.Errorf("not a valid CSW file (%d pulses decoded, %d stored)", b.PulsesNb(), pulsesNb)
+	}
+	t.Blocks = []block.Block{b}
+
+	return nil
+}
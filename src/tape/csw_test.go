@@ -0,0 +1,83 @@
+package tape
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"reflect"
+	"testing"
+)
+
+// cswV1 returns the content of a CSW v1 file
+func cswV1(samplingRate int, initialLevel byte, rle []byte) []byte {
+	content := []byte(CswSignature)
+	content = append(content, 1, 1)
+	content = binary.LittleEndian.AppendUint16(content, uint16(samplingRate))
+	content = append(content, block.CswCompressionRle, initialLevel, 0, 0, 0)
+	return append(content, rle...)
+}
+
+// cswV2 returns the content of a Z-RLE compressed CSW v2 file
+func cswV2(samplingRate int, pulsesNb int, rle []byte) []byte {
+	content := []byte(CswSignature)
+	content = append(content, 2, 0)
+	content = binary.LittleEndian.AppendUint32(content, uint32(samplingRate))
+	content = binary.LittleEndian.AppendUint32(content, uint32(pulsesNb))
+	content = append(content, block.CswCompressionZRle, 0, 0)
+	content = append(content, make([]byte, 16)...) // Encoding application
+	var z bytes.Buffer
+	w := zlib.NewWriter(&z)
+	_, _ = w.Write(rle)
+	_ = w.Close()
+	return append(content, z.Bytes()...)
+}
+
+func TestReadCsw(t *testing.T) {
+	// Pulses of 10, 20 and 300 samples
+	rle := []byte{10, 20, 0, 0x2c, 0x01, 0, 0}
+
+	tests := []struct {
+		name       string
+		content    []byte
+		pulses     []int
+		firstLevel block.Edge
+		err        string
+	}{
+		{"v1", cswV1(35000, 0, rle), []int{1000, 2000, 30000}, block.EdgeLow, ""},
+		{"v1 high", cswV1(35000, 1, rle), []int{1000, 2000, 30000}, block.EdgeHigh, ""},
+		{"v2", cswV2(44100, 3, rle), []int{793, 1587, 23810}, block.EdgeLow, ""},
+		{"no signature", []byte("Compressed Square Wave!"), nil, 0, "not a valid CSW file (no CSW signature in header)"},
+		{"unsupported version", append([]byte(CswSignature), 3, 0, 0, 0, 0, 0, 0, 0, 0), nil, 0, "not a valid CSW file (unsupported version)"},
+		{"v2 truncated header", cswV2(44100, 3, rle)[:40], nil, 0, "not a valid CSW file (truncated header)"},
+		{"v2 pulses number", cswV2(44100, 4, rle), nil, 0, "not a valid CSW file (3 pulses decoded, 4 stored)"},
+		{"v1 truncated RLE", cswV1(35000, 0, rle[:4]), nil, 0, "csw recording: truncated RLE data"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tape, ok := readTestTape(t, "test.csw", test.content, test.err)
+			if !ok {
+				return
+			}
+			if tape.Format != FormatCsw {
+				t.Errorf("format %s", tape.Format)
+			}
+			if names := blockNames(tape); !reflect.DeepEqual(names, []string{"CSW Recording"}) {
+				t.Fatalf("blocks %v", names)
+			}
+			pulses := tape.Blocks[0].Pulses()
+			if len(pulses) != len(test.pulses) {
+				t.Fatalf("%d pulses, expected %d", len(pulses), len(test.pulses))
+			}
+			for i, length := range test.pulses {
+				if pulses[i].Length != length {
+					t.Errorf("pulse %d of %d T-states, expected %d", i+1, pulses[i].Length, length)
+				}
+			}
+			if pulses[0].Edge != test.firstLevel {
+				t.Errorf("first pulse edge %d, expected %d", pulses[0].Edge, test.firstLevel)
+			}
+		})
+	}
+}
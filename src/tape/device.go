@@ -0,0 +1,108 @@
+package tape
+
+import (
+	"fmt"
+	"github.com/gordonklaus/portaudio"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Device is an audio output device the tape can be played to
+type Device struct {
+	Index              int
+	Name               string
+	HostApi            string
+	MaxOutputChannels  int
+	DefaultSampleRate  float64
+	DefaultLowLatency  time.Duration
+	DefaultHighLatency time.Duration
+	Default            bool
+}
+
+// HostApi is a PortAudio host API (ALSA, JACK, CoreAudio, WASAPI etc.) with
+// its output devices
+type HostApi struct {
+	Name    string
+	Default bool
+	Devices []Device
+}
+
+// outputDevices lists the host APIs and their output devices. PortAudio must
+// be initialized.
+func outputDevices() ([]HostApi, error) {
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, err
+	}
+	hostApis, err := portaudio.HostApis()
+	if err != nil {
+		return nil, err
+	}
+	defaultHostApi, err := portaudio.DefaultHostApi()
+	if err != nil {
+		return nil, err
+	}
+
+	apis := make([]HostApi, 0, len(hostApis))
+	for _, hostApi := range hostApis {
+		api := HostApi{
+			Name:    hostApi.Name,
+			Default: hostApi == defaultHostApi,
+		}
+		for i, d := range devices {
+			if d.HostApi != hostApi || d.MaxOutputChannels == 0 {
+				continue
+			}
+			api.Devices = append(api.Devices, Device{
+				Index:              i,
+				Name:               d.Name,
+				HostApi:            hostApi.Name,
+				MaxOutputChannels:  d.MaxOutputChannels,
+				DefaultSampleRate:  d.DefaultSampleRate,
+				DefaultLowLatency:  d.DefaultLowOutputLatency,
+				DefaultHighLatency: d.DefaultHighOutputLatency,
+				Default:            d == hostApi.DefaultOutputDevice,
+			})
+		}
+		apis = append(apis, api)
+	}
+
+	return apis, nil
+}
+
+// findOutputDevice returns the output device matching the given name: the
+// device index as listed by Service.Devices, or a part of the device name.
+// An empty name gives the default output device. PortAudio must be initialized.
+func findOutputDevice(name string) (*portaudio.DeviceInfo, error) {
+	if name == "" {
+		return portaudio.DefaultOutputDevice()
+	}
+
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, err
+	}
+
+	if index, err := strconv.Atoi(name); err == nil {
+		if index < 0 || index >= len(devices) || devices[index].MaxOutputChannels == 0 {
+			return nil, fmt.Errorf("no output device at index %d", index)
+		}
+		return devices[index], nil
+	}
+
+	var found *portaudio.DeviceInfo
+	for _, d := range devices {
+		if d.MaxOutputChannels == 0 || !strings.Contains(strings.ToLower(d.Name), strings.ToLower(name)) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("several output devices match '%s'", name)
+		}
+		found = d
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no output device matches '%s'", name)
+	}
+	return found, nil
+}
@@ -0,0 +1,155 @@
+package tape
+
+import (
+	"math"
+	"time"
+)
+
+// EventBufferSize is the number of events a subscriber channel can hold.
+// Events are dropped when the channel of a subscriber is full.
+const EventBufferSize = 64
+
+// PositionEventInterval is the minimal duration in ms of playing between two
+// EventPosition events
+const PositionEventInterval = 50
+
+// EventType is the kind of an Event sent by a Player
+type EventType int
+
+const (
+	// EventStateChanged is sent when the state of the player changes
+	EventStateChanged EventType = iota
+	// EventBlockEntered is sent when the playing reaches a block
+	EventBlockEntered
+	// EventGroupEntered is sent when the playing reaches a Group start block
+	EventGroupEntered
+	// EventMessage is sent when the playing reaches a Message block
+	EventMessage
+	// EventTapeStopped is sent when the playing reaches a "Stop the tape" point
+	EventTapeStopped
+	// EventEndOfTape is sent when the playing reaches the end of the tape
+	EventEndOfTape
+	// EventError is sent when the audio stream fails. Playing goes on.
+	EventError
+	// EventPosition is sent while playing, at most every PositionEventInterval
+	// of the tape, and when the position is moved
+	EventPosition
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventStateChanged:
+		return "state changed"
+	case EventBlockEntered:
+		return "block entered"
+	case EventGroupEntered:
+		return "group entered"
+	case EventMessage:
+		return "message"
+	case EventTapeStopped:
+		return "tape stopped"
+	case EventError:
+		return "error"
+	case EventPosition:
+		return "position"
+	default:
+		return "end of tape"
+	}
+}
+
+// Event is a notification sent by a Player to its subscribers
+type Event struct {
+	Type EventType
+
+	// State is the state of the player when the event is sent
+	State PlayerState
+
+	// Block of the event. BlockIndex is the position of the block in the tape,
+	// starting from 0. For events which are not related to a block, it is the
+	// block being played or -1.
+	BlockIndex int
+	BlockId    byte
+	BlockName  string
+
+	// Group is the name of the group of EventGroupEntered events
+	Group string
+
+	// Message is the text of EventMessage events
+	Message string
+
+	// Err is the error of EventError events
+	Err error
+
+	// Position of the event in the samples stream, and in percent of the tape
+	Sample  int64
+	Time    time.Duration
+	Percent int64
+}
+
+// Subscribe returns a channel receiving the events of the player, and the
+// function to call to stop receiving them. Events are dropped when the
+// channel is full, so it should be read continuously.
+func (p *Player) Subscribe() (<-chan Event, func()) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	events := make(chan Event, EventBufferSize)
+	p.subscribers = append(p.subscribers, events)
+
+	unsubscribe := func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		for i, s := range p.subscribers {
+			if s == events {
+				p.subscribers = append(p.subscribers[:i], p.subscribers[i+1:]...)
+				close(events)
+				break
+			}
+		}
+	}
+
+	return events, unsubscribe
+}
+
+// emitPosition sends an EventPosition event if the position moved backward or
+// by at least PositionEventInterval since the last one, or if force is set.
+// The mutex must be held.
+func (p *Player) emitPosition(force bool) {
+	pos := p.reader.Pos()
+	step := p.reader.samplesSize(p.reader.SamplingRate * PositionEventInterval / 1000)
+	if !force && pos >= p.positionSent && pos-p.positionSent < step {
+		return
+	}
+	p.positionSent = pos
+	p.emit(Event{Type: EventPosition}, pos)
+}
+
+// emit sends an event to the subscribers. The position of the event is the
+// given byte of the samples stream. The mutex must be held.
+func (p *Player) emit(event Event, pos int64) {
+	if len(p.subscribers) == 0 {
+		return
+	}
+
+	event.State = p.state
+	event.Sample = pos / p.reader.sampleSize()
+	event.Time = time.Duration(float64(event.Sample) / float64(p.reader.SamplingRate) * float64(time.Second))
+	if size := p.reader.Size(); size > 0 {
+		event.Percent = int64(math.Round(float64(pos) / float64(size) * 100))
+	}
+	if event.BlockName == "" {
+		event.BlockIndex = -1
+		if entry, ok := p.reader.entryAt(pos); ok {
+			event.BlockIndex = entry.blockIndex
+			event.BlockId = p.reader.tape.Blocks[entry.blockIndex].Id()
+			event.BlockName = entry.blockName
+		}
+	}
+
+	for _, s := range p.subscribers {
+		select {
+		case s <- event:
+		default:
+		}
+	}
+}
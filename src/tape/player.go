@@ -1,22 +1,77 @@
 package tape
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/TiBeN/tzx-player/tape/block"
 	"github.com/gordonklaus/portaudio"
 	"io"
+	"math"
+	"sync"
+	"time"
 )
 
-// Player plays a TZX file as audio samples through the sound card
+const DefaultFramesPerBuffer = 1000
+
+// SeekStepDuration is the duration in ms of the rewind and fast forward moves
+const SeekStepDuration = 1000
+
+// PlayerState is the state of a Player
+type PlayerState int
+
+const (
+	// PlayerStopped is the state of a player not started yet or stopped by Player.Stop
+	PlayerStopped PlayerState = iota
+	PlayerPlaying
+	PlayerPaused
+	// PlayerFinished is the state of a player which reached the end of the
+	// tape. It plays again when the position is moved back.
+	PlayerFinished
+)
+
+func (s PlayerState) String() string {
+	switch s {
+	case PlayerPlaying:
+		return "playing"
+	case PlayerPaused:
+		return "paused"
+	case PlayerFinished:
+		return "finished"
+	default:
+		return "stopped"
+	}
+}
+
+// Player plays a TZX file as audio samples through the sound card.
+// Its methods can be called from several goroutines: the state of the player
+// and the reader are protected by a mutex. The playing loop waits while the
+// player is paused or has finished the tape, until it is stopped.
+// The samples are rendered without holding the mutex, so the controls don't
+// wait for the rendering of long blocks. The rendering mutex keeps the blocks
+// index unchanged meanwhile.
 type Player struct {
-	reader   *Reader
-	playing  bool
-	pause    bool
-	stop     bool
-	savedPos int64
+	reader      *Reader
+	options     PlayerOptions
+	mutex       sync.Mutex
+	rendering   sync.Mutex
+	changed     *sync.Cond
+	state       PlayerState
+	tapeStopped bool
+	savedPos    int64
+	done        chan struct{}
+	subscribers []chan Event
+	nextEntry   int
+
+	// positionSent is the position of the last EventPosition event
+	positionSent int64
 }
 
 type PlayerInfos struct {
+	State        PlayerState
 	Playing      bool
 	Pause        bool
+	TapeStopped  bool
 	CurrentByte  int64
 	TotalBytes   int64
 	PosPercent   int64
@@ -24,89 +79,336 @@ type PlayerInfos struct {
 	TotalSeconds int64
 	FileName     string
 	BlockInfo    string
+
+	// Selections holds the descriptions of the entries of the Select block the
+	// player waits a choice for. It is nil when no choice is expected.
+	Selections []string
+}
+
+// PlayerOptions holds the parameters of the audio output
+type PlayerOptions struct {
+	// Device is the output device: its index as listed by Service.Devices, or
+	// a part of its name. Empty means the default output device.
+	Device string
+
+	// FramesPerBuffer is the size of the audio buffer in samples
+	FramesPerBuffer int
+
+	// Latency is the suggested output latency. 0 means the default latency of
+	// the device.
+	Latency time.Duration
 }
 
-func NewPlayer(reader *Reader) *Player {
-	return &Player{
+func NewPlayer(reader *Reader, options PlayerOptions) *Player {
+	if options.FramesPerBuffer == 0 {
+		options.FramesPerBuffer = DefaultFramesPerBuffer
+	}
+	p := &Player{
 		reader:  reader,
-		playing: false,
+		options: options,
+		state:   PlayerStopped,
+	}
+	p.changed = sync.NewCond(&p.mutex)
+
+	// The reader may start after the first blocks
+	if entry, ok := reader.entryAt(reader.Pos()); ok {
+		p.nextEntry = reader.firstEntryIndexFrom(entry.blockByte)
 	}
+	return p
 }
 
 // Start playing the tape
 func (p *Player) Start() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.done != nil {
+		return errors.New("player already started")
+	}
+
 	if err := portaudio.Initialize(); err != nil {
 		return err
 	}
 
-	buf := make([]byte, 1000)
-	stream, err := portaudio.OpenDefaultStream(
-		0,
-		1,
-		float64(p.reader.SamplingRate),
-		len(buf),
-		&buf,
-	)
+	stream, buf, decode, err := p.openStream()
 	if err != nil {
+		_ = portaudio.Terminate()
 		return err
 	}
 
 	if err = stream.Start(); err != nil {
+		_ = stream.Close()
+		_ = portaudio.Terminate()
 		return err
 	}
-	p.playing = true
+	p.setState(PlayerPlaying)
+	p.done = make(chan struct{})
 
 	// Main playing loop
 	go func() {
-		for {
-
-			// Handle pause
-			if p.pause {
-				continue
+		defer close(p.done)
+		for p.fill(buf) {
+			decode(buf)
+			if err := stream.Write(); err != nil {
+				p.reportError(err)
 			}
+		}
 
-			_, err = p.reader.Read(buf)
-			if err = stream.Write(); err != nil {
-				//panic(err)
-			}
-			if err == io.EOF || p.stop {
-				break
-			}
+		if err := stream.Stop(); err != nil {
+			p.reportError(err)
+		}
+		if err := stream.Close(); err != nil {
+			p.reportError(err)
+		}
+		if err := portaudio.Terminate(); err != nil {
+			p.reportError(err)
 		}
+	}()
+
+	return nil
+}
 
-		if err = stream.Stop(); err != nil {
-			//panic(err)
+// reportError sends an EventError event for an error of the audio stream
+func (p *Player) reportError(err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.emit(Event{Type: EventError, Err: err}, p.reader.Pos())
+}
+
+// openStream opens the audio stream of the output device. It returns the
+// buffer the samples are read into, and the function decoding them into the
+// stream buffer.
+func (p *Player) openStream() (*portaudio.Stream, []byte, func(samples []byte), error) {
+	device, err := findOutputDevice(p.options.Device)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	buf := make([]byte, p.options.FramesPerBuffer*int(p.reader.sampleSize()))
+	streamBuf, decode := newStreamBuffer(p.reader.bitDepth, p.options.FramesPerBuffer)
+	params := portaudio.HighLatencyParameters(nil, device)
+	params.Output.Channels = 1
+	params.SampleRate = float64(p.reader.SamplingRate)
+	params.FramesPerBuffer = p.options.FramesPerBuffer
+	if p.options.Latency > 0 {
+		params.Output.Latency = p.options.Latency
+	}
+
+	if err = portaudio.IsFormatSupported(params, streamBuf); err != nil {
+		return nil, nil, nil, fmt.Errorf("device '%s' doesn't support a sampling rate of %d Hz with %d bit samples: %s", device.Name, p.reader.SamplingRate, p.reader.bitDepth, err.Error())
+	}
+
+	stream, err := portaudio.OpenStream(params, streamBuf)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return stream, buf, decode, nil
+}
+
+// fill waits while the player is paused or has finished the tape, then reads
+// the next samples into the given buffer. It returns false when the player is
+// stopped.
+func (p *Player) fill(buf []byte) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for {
+		for p.state == PlayerPaused || p.state == PlayerFinished {
+			p.changed.Wait()
 		}
-		if err = stream.Close(); err != nil {
-			//panic(err)
+		if p.state != PlayerPlaying {
+			return false
 		}
 
-		p.playing = false
-	}()
+		// Don't read further than the next "Stop the tape" point
+		pos := p.reader.Pos()
+		readBuf := buf
+		nextStop := p.reader.NextStop()
+		if nextStop >= 0 && nextStop-pos < int64(len(buf)) {
+			readBuf = buf[:nextStop-pos]
+		}
+
+		p.mutex.Unlock()
+		p.rendering.Lock()
+		n := p.reader.readAt(readBuf, pos)
+		p.rendering.Unlock()
+		p.mutex.Lock()
+
+		// Read again when the position changed meanwhile
+		if p.state != PlayerPlaying || p.reader.Pos() != pos {
+			continue
+		}
+		_, _ = p.reader.Seek(pos+int64(n), io.SeekStart)
+		p.reader.silence(buf[n:])
+		p.enterBlocks()
+		p.emitPosition(false)
+		p.afterRead(nextStop)
+		return true
+	}
+}
+
+// afterRead changes the state of the player when the samples read reached the
+// end of the tape, a Select block or a "Stop the tape" point
+func (p *Player) afterRead(nextStop int64) {
+	if p.reader.Pos() >= p.reader.Size() {
+		if p.reader.PendingSelect() != nil {
+			// Wait for a choice when a Select block is reached
+			p.setState(PlayerPaused)
+		} else {
+			p.setState(PlayerFinished)
+			p.emit(Event{Type: EventEndOfTape}, p.reader.Pos())
+		}
+	} else if nextStop >= 0 && p.reader.Pos() == nextStop {
+		p.tapeStopped = true
+		p.setState(PlayerPaused)
+		p.emit(Event{Type: EventTapeStopped}, nextStop)
+	}
+}
+
+// setState changes the state of the player and wakes up the playing loop.
+// The mutex must be held.
+func (p *Player) setState(state PlayerState) {
+	if state == p.state {
+		return
+	}
+	p.state = state
+	p.changed.Broadcast()
+	p.emit(Event{Type: EventStateChanged}, p.reader.Pos())
+}
+
+// enterBlocks sends the events of the blocks reached since the last call.
+// The mutex must be held.
+func (p *Player) enterBlocks() {
+	pos := p.reader.Pos()
+	for ; p.nextEntry < len(p.reader.blocksBytes); p.nextEntry++ {
+		entry := p.reader.blocksBytes[p.nextEntry]
+		if entry.blockByte > pos {
+			break
+		}
+
+		b := p.reader.tape.Blocks[entry.blockIndex]
+		event := Event{
+			Type:       EventBlockEntered,
+			BlockIndex: entry.blockIndex,
+			BlockId:    b.Id(),
+			BlockName:  entry.blockName,
+		}
+		p.emit(event, entry.blockByte)
+
+		switch b := b.(type) {
+		case *block.GroupStart:
+			event.Type = EventGroupEntered
+			event.Group = b.GroupName()
+			p.emit(event, entry.blockByte)
+		case *block.MessageBlock:
+			event.Type = EventMessage
+			event.Message = b.Message()
+			p.emit(event, entry.blockByte)
+		}
+	}
+}
 
+// seek changes the position of the reader, and plays again when the player
+// had finished the tape. The block at the new position is entered again.
+// The mutex must be held.
+func (p *Player) seek(offset int64, whence int) error {
+	pos, err := p.reader.Seek(offset, whence)
+	if err != nil {
+		return err
+	}
+	p.nextEntry = 0
+	if entry, ok := p.reader.entryAt(pos); ok {
+		p.nextEntry = p.reader.firstEntryIndexFrom(entry.blockByte)
+	}
+	p.enterBlocks()
+	p.emitPosition(true)
+	if p.state == PlayerFinished && pos < p.reader.Size() {
+		p.setState(PlayerPlaying)
+	}
 	return nil
 }
 
+// State returns the current state of the player
+func (p *Player) State() PlayerState {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.state
+}
+
 func (p *Player) TogglePause() {
-	p.pause = !p.pause
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	switch p.state {
+	case PlayerPlaying:
+		p.setState(PlayerPaused)
+	case PlayerPaused:
+		p.tapeStopped = false
+		p.setState(PlayerPlaying)
+	}
 }
 
 func (p *Player) Pause() {
-	p.pause = true
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.state == PlayerPlaying {
+		p.setState(PlayerPaused)
+	}
 }
 
 func (p *Player) Resume() {
-	p.pause = false
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.state == PlayerPaused {
+		p.tapeStopped = false
+		p.setState(PlayerPlaying)
+	}
 }
 
+// Stop stops the playing and waits for the audio stream to be closed
 func (p *Player) Stop() {
-	p.stop = true
+	p.mutex.Lock()
+	if p.state != PlayerStopped {
+		p.setState(PlayerStopped)
+	}
+	done := p.done
+	p.mutex.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// Select chooses the entry (starting from 1) of the Select block the player
+// is waiting at, then resumes playing
+func (p *Player) Select(selection int) error {
+	p.rendering.Lock()
+	defer p.rendering.Unlock()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if err := p.reader.Select(selection); err != nil {
+		return err
+	}
+	if p.state == PlayerPaused {
+		p.setState(PlayerPlaying)
+	}
+	return nil
 }
 
 func (p *Player) Infos() PlayerInfos {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var selections []string
+	if s := p.reader.PendingSelect(); s != nil && p.reader.Pos() == p.reader.Size() {
+		for _, selection := range s.Selections() {
+			selections = append(selections, selection.Description())
+		}
+	}
+
 	return PlayerInfos{
-		Playing:      p.playing,
-		Pause:        p.pause,
+		State:        p.state,
+		Playing:      p.state == PlayerPlaying || p.state == PlayerPaused,
+		Pause:        p.state == PlayerPaused,
+		TapeStopped:  p.tapeStopped,
 		CurrentByte:  p.reader.Pos(),
 		TotalBytes:   p.reader.Size(),
 		PosPercent:   p.reader.PosPercent(),
@@ -114,15 +416,123 @@ func (p *Player) Infos() PlayerInfos {
 		TotalSeconds: p.reader.TotalSeconds(),
 		FileName:     p.reader.FileName(),
 		BlockInfo:    p.reader.BlockInfo(),
+		Selections:   selections,
 	}
 }
 
+// Rewind moves the tape backward by SeekStepDuration
 func (p *Player) Rewind() {
-	_, _ = p.reader.Seek(-50000, 1)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pos := p.reader.Pos() - p.seekStep()
+	if pos < 0 {
+		pos = 0
+	}
+	_ = p.seek(pos, io.SeekStart)
 }
 
+// FastForward moves the tape forward by SeekStepDuration
 func (p *Player) FastForward() {
-	_, _ = p.reader.Seek(50000, 1)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pos := p.reader.Pos() + p.seekStep()
+	if pos > p.reader.Size() {
+		pos = p.reader.Size()
+	}
+	_ = p.seek(pos, io.SeekStart)
+}
+
+// seekStep returns the size in bytes of the rewind and fast forward moves
+func (p *Player) seekStep() int64 {
+	return p.reader.samplesSize(p.reader.SamplingRate * SeekStepDuration / 1000)
+}
+
+// NextBlock goes to the start of the next block
+func (p *Player) NextBlock() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pos := p.reader.Pos()
+	for _, entry := range p.reader.blocksBytes {
+		if entry.blockByte > pos {
+			_ = p.seek(entry.blockByte, io.SeekStart)
+			return
+		}
+	}
+}
+
+// PreviousBlock goes to the start of the block preceding the current one
+func (p *Player) PreviousBlock() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	current, ok := p.reader.entryAt(p.reader.Pos())
+	if !ok {
+		return
+	}
+	target := int64(0)
+	for _, entry := range p.reader.blocksBytes {
+		if entry.blockByte >= current.blockByte {
+			break
+		}
+		target = entry.blockByte
+	}
+	_ = p.seek(target, io.SeekStart)
+}
+
+// BlockStart goes to the start of the current block
+func (p *Player) BlockStart() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if current, ok := p.reader.entryAt(p.reader.Pos()); ok {
+		_ = p.seek(current.blockByte, io.SeekStart)
+	}
+}
+
+// NextGroup goes to the start of the next group of blocks
+func (p *Player) NextGroup() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pos := p.reader.Pos()
+	for _, entry := range p.reader.blocksBytes {
+		if _, ok := p.reader.tape.Blocks[entry.blockIndex].(*block.GroupStart); ok && entry.blockByte > pos {
+			_ = p.seek(entry.blockByte, io.SeekStart)
+			return
+		}
+	}
+}
+
+// PreviousGroup goes to the start of the group of blocks preceding the
+// current one
+func (p *Player) PreviousGroup() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pos := p.reader.Pos()
+	groupStarts := make([]int64, 0)
+	for _, entry := range p.reader.blocksBytes {
+		if entry.blockByte > pos {
+			break
+		}
+		if _, ok := p.reader.tape.Blocks[entry.blockIndex].(*block.GroupStart); ok {
+			groupStarts = append(groupStarts, entry.blockByte)
+		}
+	}
+
+	// The last group start is the one of the current group
+	if len(groupStarts) >= 2 {
+		_ = p.seek(groupStarts[len(groupStarts)-2], io.SeekStart)
+	}
+}
+
+// GoToBlock goes to the start of the given block number of the tape, starting
+// from 1. If the block is played several times, the first time is chosen.
+func (p *Player) GoToBlock(blockNb int) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, entry := range p.reader.blocksBytes {
+		if entry.blockIndex == blockNb-1 {
+			return p.seek(entry.blockByte, io.SeekStart)
+		}
+	}
+	return fmt.Errorf("block %d is not played", blockNb)
 }
 
 // SaveCurrentPos saves the current position in memory.
@@ -130,6 +540,8 @@ func (p *Player) FastForward() {
 // This emulates a real Counter as seen on tape players and can be used to rewind the tape
 // at a specific position. Useful for multi-load levels games for example.
 func (p *Player) SaveCurrentPos() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	p.savedPos = p.reader.Pos()
 }
 
@@ -137,6 +549,41 @@ func (p *Player) SaveCurrentPos() {
 // with Player.SaveCurrentPos. If no position was previously stored, it rewinds the
 // tape to beginning.
 func (p *Player) GoToSavedPos() error {
-	_, err := p.reader.Seek(p.savedPos, 0)
-	return err
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.seek(p.savedPos, io.SeekStart)
+}
+
+// newStreamBuffer returns the PortAudio buffer matching the samples format of
+// the given bit depth, and the function decoding the samples given by the
+// reader into this buffer
+func newStreamBuffer(bitDepth int, frames int) (interface{}, func(samples []byte)) {
+	switch bitDepth {
+	case 8:
+		buf := make([]uint8, frames)
+		return &buf, func(samples []byte) {
+			copy(buf, samples)
+		}
+	case 16:
+		buf := make([]int16, frames)
+		return &buf, func(samples []byte) {
+			for i := range buf {
+				buf[i] = int16(binary.LittleEndian.Uint16(samples[i*2:]))
+			}
+		}
+	case 24:
+		buf := make([]portaudio.Int24, frames)
+		return &buf, func(samples []byte) {
+			for i := range buf {
+				buf[i].PutInt32(int32(uint32(samples[i*3])<<8 | uint32(samples[i*3+1])<<16 | uint32(samples[i*3+2])<<24))
+			}
+		}
+	default: // 32 bit float
+		buf := make([]float32, frames)
+		return &buf, func(samples []byte) {
+			for i := range buf {
+				buf[i] = math.Float32frombits(binary.LittleEndian.Uint32(samples[i*4:]))
+			}
+		}
+	}
 }
@@ -0,0 +1,262 @@
+package tape
+
+import (
+	"github.com/gordonklaus/portaudio"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startPlaying runs the playing loop of Player.Start, without the audio stream
+func startPlaying(player *Player) {
+	player.mutex.Lock()
+	player.setState(PlayerPlaying)
+	player.done = make(chan struct{})
+	player.mutex.Unlock()
+	go func() {
+		defer close(player.done)
+		buf := make([]byte, player.options.FramesPerBuffer*int(player.reader.sampleSize()))
+		for player.fill(buf) {
+		}
+	}()
+}
+
+// waitState waits for the player to reach the given state
+func waitState(t *testing.T, player *Player, state PlayerState) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for player.State() != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("state %s, expected %s", player.State(), state)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestPlayerConcurrentControls calls the controls of a player from several
+// goroutines while the playing loop reads the samples. Run it with -race.
+func TestPlayerConcurrentControls(t *testing.T) {
+	tape := newTestTape(t, "test.tzx", tzx(
+		tzxPureTone(2168, 200),
+		tzxPause(100),
+		tzxPureTone(2168, 200),
+		tzxPause(100),
+		tzxPureTone(2168, 200),
+	))
+	reader, err := NewReader(tape, ReaderOptions{SamplingRate: 44100, BitDepth: 16, SpeedFactor: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := NewPlayer(reader, PlayerOptions{FramesPerBuffer: 64})
+	events, unsubscribe := player.Subscribe()
+	startPlaying(player)
+
+	received := make(chan int)
+	go func() {
+		n := 0
+		for range events {
+			n++
+		}
+		received <- n
+	}()
+
+	controls := []func(){
+		player.TogglePause,
+		player.Pause,
+		player.Resume,
+		player.Rewind,
+		player.FastForward,
+		player.SaveCurrentPos,
+		func() { _ = player.GoToSavedPos() },
+		func() { _ = player.Select(1) },
+		func() { _ = player.Infos() },
+		func() { _ = player.State() },
+	}
+	var wg sync.WaitGroup
+	for _, control := range controls {
+		wg.Add(1)
+		go func(control func()) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				control()
+			}
+		}(control)
+	}
+	wg.Wait()
+
+	player.Stop()
+	if state := player.State(); state != PlayerStopped {
+		t.Errorf("state %s after Stop", state)
+	}
+	unsubscribe()
+	if n := <-received; n == 0 {
+		t.Error("no event received")
+	}
+}
+
+func TestPlayerPlaysAgainAfterTheEnd(t *testing.T) {
+	tape := newTestTape(t, "test.tzx", tzx(tzxPureTone(2168, 100)))
+	reader, err := NewReader(tape, ReaderOptions{SamplingRate: 44100, BitDepth: 8, SpeedFactor: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := NewPlayer(reader, PlayerOptions{FramesPerBuffer: 256})
+	startPlaying(player)
+
+	waitState(t, player, PlayerFinished)
+	if pos := reader.Pos(); pos != reader.Size() {
+		t.Errorf("finished at %d, expected %d", pos, reader.Size())
+	}
+
+	// Moving back plays the tape again until its end
+	if err := player.GoToSavedPos(); err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, player, PlayerFinished)
+	select {
+	case <-player.done:
+		t.Fatal("playing loop over before Stop")
+	default:
+	}
+
+	player.Stop()
+	if state := player.State(); state != PlayerStopped {
+		t.Errorf("state %s after Stop", state)
+	}
+}
+
+func TestNewStreamBuffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		bitDepth int
+		samples  []byte
+		expected interface{}
+	}{
+		{"8 bit", 8, []byte{0x00, 0xff, 0x80}, &[]uint8{0x00, 0xff, 0x80}},
+		{"16 bit", 16, []byte{0x00, 0x80, 0x00, 0x7f, 0xff, 0xff}, &[]int16{-32768, 0x7f00, -1}},
+		{"24 bit", 24, []byte{0x00, 0x00, 0x80, 0xff, 0xff, 0x7f, 0x01, 0x02, 0x03},
+			&[]portaudio.Int24{{0x00, 0x00, 0x80}, {0xff, 0xff, 0x7f}, {0x01, 0x02, 0x03}}},
+		{"32 bit float", 32, []byte{0x00, 0x00, 0x80, 0xbf, 0x00, 0x00, 0x80, 0x3f, 0x00, 0x00, 0x00, 0x3f},
+			&[]float32{-1, 1, 0.5}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf, decode := newStreamBuffer(test.bitDepth, 3)
+			decode(test.samples)
+			if !reflect.DeepEqual(buf, test.expected) {
+				t.Errorf("decoded %v, expected %v", buf, test.expected)
+			}
+		})
+	}
+}
+
+func TestPlayerPositionEvents(t *testing.T) {
+	tape := newTestTape(t, "test.tzx", tzx(tzxPureTone(2168, 2000)))
+	reader, err := NewReader(tape, ReaderOptions{SamplingRate: 44100, BitDepth: 8, SpeedFactor: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := NewPlayer(reader, PlayerOptions{FramesPerBuffer: 100})
+	events, unsubscribe := player.Subscribe()
+	defer unsubscribe()
+
+	player.mutex.Lock()
+	player.setState(PlayerPlaying)
+	player.mutex.Unlock()
+	buf := make([]byte, player.options.FramesPerBuffer)
+	for player.fill(buf) {
+		if player.State() == PlayerFinished {
+			break
+		}
+	}
+
+	step := time.Duration(PositionEventInterval) * time.Millisecond
+	positions := make([]time.Duration, 0)
+	for len(events) > 0 {
+		if event := <-events; event.Type == EventPosition {
+			positions = append(positions, event.Time)
+		}
+	}
+	total := time.Duration(reader.Size() / reader.sampleSize() * int64(time.Second) / int64(reader.SamplingRate))
+	// Events are sent at the end of a buffer, so they are a bit more spaced
+	if n := len(positions); n < int(total/step)*9/10 || n > int(total/step) {
+		t.Fatalf("%d position events for %s of tape", n, total)
+	}
+	for i := 1; i < len(positions); i++ {
+		if positions[i]-positions[i-1] < step {
+			t.Errorf("position events at %s and %s", positions[i-1], positions[i])
+		}
+	}
+
+	// Moving the position sends an event at once
+	player.Rewind()
+	event := <-events
+	for event.Type == EventBlockEntered && len(events) > 0 {
+		event = <-events
+	}
+	if event.Type != EventPosition || event.Sample*reader.sampleSize() != reader.Pos() {
+		t.Errorf("event %s at sample %d after rewind", event.Type, event.Sample)
+	}
+}
+
+func TestPlayerNavigation(t *testing.T) {
+	tape := newTestTape(t, "test.tzx", tzx(
+		tzxPureTone(2168, 2000),
+		[]byte{0x21, 1, 'A'}, // Group start
+		tzxPureTone(2168, 2000),
+		[]byte{0x22}, // Group end
+		[]byte{0x21, 1, 'B'},
+		tzxPureTone(2168, 2000),
+		[]byte{0x22},
+	))
+	reader, err := NewReader(tape, ReaderOptions{SamplingRate: 8000, BitDepth: 8, SpeedFactor: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := NewPlayer(reader, PlayerOptions{})
+	step := int64(8000 * SeekStepDuration / 1000)
+	block := func(blockNb int) int64 {
+		return reader.blocksBytes[blockNb-1].blockByte
+	}
+
+	tests := []struct {
+		name     string
+		from     int64
+		move     func()
+		expected int64
+	}{
+		{"rewind", 3 * step, player.Rewind, 2 * step},
+		{"rewind to the beginning", step / 2, player.Rewind, 0},
+		{"rewind at the beginning", 0, player.Rewind, 0},
+		{"fast forward", step, player.FastForward, 2 * step},
+		{"fast forward to the end", reader.Size() - step/2, player.FastForward, reader.Size()},
+		{"fast forward at the end", reader.Size(), player.FastForward, reader.Size()},
+		{"next block", block(1) + 10, player.NextBlock, block(2)},
+		{"next block at the last one", block(7), player.NextBlock, block(7)},
+		{"previous block", block(6) + 10, player.PreviousBlock, block(3)},
+		{"previous block at the first one", block(1) + 10, player.PreviousBlock, 0},
+		{"block start", block(3) + 10, player.BlockStart, block(3)},
+		{"next group", block(1) + 10, player.NextGroup, block(2)},
+		{"next group in a group", block(3) + 10, player.NextGroup, block(5)},
+		{"next group in the last one", block(6) + 10, player.NextGroup, block(6) + 10},
+		{"previous group", block(6) + 10, player.PreviousGroup, block(2)},
+		{"previous group in the first one", block(3) + 10, player.PreviousGroup, block(3) + 10},
+		{"go to block", 0, func() { _ = player.GoToBlock(6) }, block(6)},
+		{"go to a block not played", block(3), func() { _ = player.GoToBlock(8) }, block(3)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := reader.Seek(test.from, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			test.move()
+			if pos := reader.Pos(); pos != test.expected {
+				t.Errorf("position %d, expected %d", pos, test.expected)
+			}
+		})
+	}
+}
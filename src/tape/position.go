@@ -0,0 +1,154 @@
+package tape
+
+import (
+	"fmt"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PositionType is the kind of a Position
+type PositionType int
+
+const (
+	PositionBlock PositionType = iota
+	PositionGroup
+	PositionTime
+)
+
+// Position is a position in a tape given by a block number, a group name or
+// a time from the beginning of the tape
+type Position struct {
+	Type PositionType
+
+	// Block is the block number, starting from 1
+	Block int
+
+	// Group is the name of the group
+	Group string
+
+	// Time is the time from the beginning of the tape
+	Time time.Duration
+}
+
+// ParsePosition parses a position given as "block:N", "group:NAME", or a
+// time as "[[h:]m:]s[.fraction]"
+func ParsePosition(position string) (Position, error) {
+	switch {
+	case strings.HasPrefix(position, "block:"):
+		blockNb, err := strconv.Atoi(strings.TrimPrefix(position, "block:"))
+		if err != nil || blockNb < 1 {
+			return Position{}, fmt.Errorf("invalid block number in position '%s'", position)
+		}
+		return Position{Type: PositionBlock, Block: blockNb}, nil
+	case strings.HasPrefix(position, "group:"):
+		return Position{Type: PositionGroup, Group: strings.TrimPrefix(position, "group:")}, nil
+	}
+
+	parts := strings.Split(position, ":")
+	if len(parts) > 3 {
+		return Position{}, fmt.Errorf("invalid position '%s'", position)
+	}
+	seconds := 0.0
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil || !(value >= 0) || math.IsInf(value, 1) || (i < len(parts)-1 && value != math.Trunc(value)) {
+			return Position{}, fmt.Errorf("invalid position '%s'", position)
+		}
+		seconds = seconds*60 + value
+	}
+	if seconds >= math.MaxInt64/float64(time.Second) {
+		return Position{}, fmt.Errorf("invalid position '%s'", position)
+	}
+	return Position{Type: PositionTime, Time: time.Duration(seconds * float64(time.Second))}, nil
+}
+
+func (p Position) String() string {
+	switch p.Type {
+	case PositionBlock:
+		return fmt.Sprintf("block:%d", p.Block)
+	case PositionGroup:
+		return fmt.Sprintf("group:%s", p.Group)
+	default:
+		return p.Time.String()
+	}
+}
+
+// resolvePosition returns the position in the samples stream of the given
+// position. The end of the block or group is given when end is true, its
+// start otherwise.
+func (r *Reader) resolvePosition(position Position, end bool) (int64, error) {
+	notFound := fmt.Errorf("position '%s' not found in the tape", position)
+
+	switch position.Type {
+	case PositionTime:
+		samples := int64(math.Round(position.Time.Seconds() * float64(r.SamplingRate)))
+		pos := samples * r.sampleSize()
+		if pos > r.size {
+			return 0, notFound
+		}
+		return pos, nil
+
+	case PositionBlock:
+		for i, entry := range r.blocksBytes {
+			if entry.blockIndex == position.Block-1 {
+				if end {
+					return r.entryEnd(i), nil
+				}
+				return entry.blockByte, nil
+			}
+		}
+
+	case PositionGroup:
+		for i, entry := range r.blocksBytes {
+			g, ok := r.tape.Blocks[entry.blockIndex].(*block.GroupStart)
+			if !ok || g.GroupName() != position.Group {
+				continue
+			}
+			if !end {
+				return entry.blockByte, nil
+			}
+			// Groups can't be nested: the group ends at the next group end
+			for j := i + 1; j < len(r.blocksBytes); j++ {
+				if _, ok := r.tape.Blocks[r.blocksBytes[j].blockIndex].(*block.GroupEnd); ok {
+					return r.entryEnd(j), nil
+				}
+			}
+			return r.blocksEnd, nil
+		}
+	}
+
+	// The blocks following a Select block are only indexed once an entry is
+	// chosen
+	if r.program.waitingSelect() != nil && r.inTape(position) {
+		return 0, fmt.Errorf("position '%s' is behind a Select block, it can't be reached before an entry is chosen", position)
+	}
+	return 0, notFound
+}
+
+// inTape returns true if the block or the group of the given position is in
+// the tape, played or not
+func (r *Reader) inTape(position Position) bool {
+	switch position.Type {
+	case PositionBlock:
+		return position.Block <= len(r.tape.Blocks)
+	case PositionGroup:
+		for _, b := range r.tape.Blocks {
+			if g, ok := b.(*block.GroupStart); ok && g.GroupName() == position.Group {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// entryEnd returns the position of the end of the block of the given block
+// position table entry
+func (r *Reader) entryEnd(i int) int64 {
+	if i+1 < len(r.blocksBytes) {
+		return r.blocksBytes[i+1].blockByte
+	}
+	return r.blocksEnd
+}
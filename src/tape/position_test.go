@@ -0,0 +1,103 @@
+package tape
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolvePositionBehindSelect(t *testing.T) {
+	// Select block with one entry jumping to the next block
+	selectBlock := []byte{0x28, 6, 0, 1, 1, 0, 2, 'G', 'o'}
+	tape := newTestTape(t, "test.tzx", tzx(
+		tzxPureTone(2168, 10),
+		selectBlock,
+		[]byte{0x21, 4, 'G', 'a', 'm', 'e'}, // Group start
+		tzxPureTone(2168, 10),
+		[]byte{0x22}, // Group end
+	))
+
+	tests := []struct {
+		position string
+		err      string
+	}{
+		{"block:1", ""},
+		{"block:3", "behind a Select block"},
+		{"group:Game", "behind a Select block"},
+		{"block:6", "not found"},
+		{"group:Intro", "not found"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.position, func(t *testing.T) {
+			position, err := ParsePosition(test.position)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = NewReader(tape, ReaderOptions{
+				SamplingRate: 44100,
+				BitDepth:     8,
+				SpeedFactor:  1,
+				Selection:    SelectionInteractive,
+				From:         &position,
+			})
+			if test.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("error %v, expected %q", err, test.err)
+			}
+		})
+	}
+
+	// Without interactive selection, the blocks after the Select block are
+	// played
+	position, _ := ParsePosition("group:Game")
+	if _, err := NewReader(tape, ReaderOptions{SamplingRate: 44100, BitDepth: 8, SpeedFactor: 1, From: &position}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	tests := []struct {
+		position string
+		expected Position
+		err      string
+	}{
+		{"block:3", Position{Type: PositionBlock, Block: 3}, ""},
+		{"group:Level 2", Position{Type: PositionGroup, Group: "Level 2"}, ""},
+		{"1:02:03.5", Position{Type: PositionTime, Time: time.Hour + 2*time.Minute + 3500*time.Millisecond}, ""},
+		{"90", Position{Type: PositionTime, Time: 90 * time.Second}, ""},
+		{"block:0", Position{}, "invalid block number"},
+		{"block:A", Position{}, "invalid block number"},
+		{"1:2:3:4", Position{}, "invalid position"},
+		{"1.5:00", Position{}, "invalid position"},
+		{"-1", Position{}, "invalid position"},
+		{"Inf", Position{}, "invalid position"},
+		{"+Inf", Position{}, "invalid position"},
+		{"1:Inf", Position{}, "invalid position"},
+		{"NaN", Position{}, "invalid position"},
+		{"1e300", Position{}, "invalid position"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.position, func(t *testing.T) {
+			position, err := ParsePosition(test.position)
+			if test.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if position != test.expected {
+					t.Errorf("position %+v, expected %+v", position, test.expected)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("error %v, expected %q", err, test.err)
+			}
+		})
+	}
+}
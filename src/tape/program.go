@@ -0,0 +1,161 @@
+package tape
+
+import (
+	"errors"
+	"fmt"
+	"github.com/TiBeN/tzx-player/tape/block"
+)
+
+// MaxExecutedBlocks is the maximum number of blocks a tape program can
+// execute. It prevents never ending jumps or loops to hang the player.
+const MaxExecutedBlocks = 1 << 20
+
+// SelectionInteractive makes the program wait at Select blocks until an entry
+// is chosen with program.choose
+const SelectionInteractive = -1
+
+// SelectionNone makes the program ignore Select blocks
+const SelectionNone = 0
+
+// program is the playback "program counter" of a tape. It walks the tape blocks
+// following the flow control blocks (jumps, loops, call sequences) and gives the
+// blocks in the order they must be played.
+type program struct {
+	tape          *Tape
+	pc            int
+	loops         []loop
+	calls         []call
+	executedCount int
+	selection     int
+	pendingSelect int
+}
+
+type loop struct {
+	firstBlock  int
+	repetitions int
+}
+
+type call struct {
+	callBlock int
+	offsets   []int
+	current   int
+}
+
+// newProgram creates the program of the given tape. selection is the entry
+// (starting from 1) to choose at every Select block, or SelectionInteractive
+// or SelectionNone.
+func newProgram(tape *Tape, selection int) *program {
+	return &program{
+		tape:          tape,
+		selection:     selection,
+		pendingSelect: -1,
+	}
+}
+
+// next returns the index of the next block to play. ok is false when the
+// end of the tape is reached or when the program waits for a selection.
+func (p *program) next() (index int, ok bool, err error) {
+	if p.pc >= len(p.tape.Blocks) || p.pendingSelect >= 0 {
+		return 0, false, nil
+	}
+
+	p.executedCount++
+	if p.executedCount > MaxExecutedBlocks {
+		return 0, false, errors.New("tape flow control never reaches the end of the tape")
+	}
+
+	index = p.pc
+	switch b := p.tape.Blocks[index].(type) {
+	case *block.Jump:
+		if b.Offset() == 0 {
+			return 0, false, fmt.Errorf("block %d: infinite jump", index+1)
+		}
+		err = p.goTo(index + b.Offset())
+	case *block.LoopStart:
+		p.loops = append(p.loops, loop{firstBlock: index + 1, repetitions: b.Repetitions()})
+		p.pc++
+	case *block.LoopEnd:
+		if len(p.loops) == 0 {
+			return 0, false, fmt.Errorf("block %d: loop end without loop start", index+1)
+		}
+		l := &p.loops[len(p.loops)-1]
+		l.repetitions--
+		if l.repetitions > 0 {
+			p.pc = l.firstBlock
+		} else {
+			p.loops = p.loops[:len(p.loops)-1]
+			p.pc++
+		}
+	case *block.CallSequence:
+		if len(b.Offsets()) == 0 {
+			p.pc++
+			break
+		}
+		p.calls = append(p.calls, call{callBlock: index, offsets: b.Offsets()})
+		err = p.goTo(index + b.Offsets()[0])
+	case *block.ReturnFromSequence:
+		if len(p.calls) == 0 {
+			return 0, false, fmt.Errorf("block %d: return from sequence without call sequence", index+1)
+		}
+		c := &p.calls[len(p.calls)-1]
+		c.current++
+		if c.current < len(c.offsets) {
+			err = p.goTo(c.callBlock + c.offsets[c.current])
+		} else {
+			p.pc = c.callBlock + 1
+			p.calls = p.calls[:len(p.calls)-1]
+		}
+	case *block.Select:
+		switch {
+		case p.selection == SelectionNone:
+			p.pc++
+		case p.selection == SelectionInteractive:
+			p.pendingSelect = index
+		default:
+			p.pendingSelect = index
+			err = p.choose(p.selection)
+		}
+	default:
+		p.pc++
+	}
+
+	if err != nil {
+		return 0, false, fmt.Errorf("block %d: %s", index+1, err.Error())
+	}
+	return index, true, nil
+}
+
+// waitingSelect returns the Select block the program is waiting a choice for,
+// or nil if it doesn't wait
+func (p *program) waitingSelect() *block.Select {
+	if p.pendingSelect < 0 {
+		return nil
+	}
+	return p.tape.Blocks[p.pendingSelect].(*block.Select)
+}
+
+// choose selects the entry (starting from 1) of the Select block the program
+// is waiting at, then moves the program to the block of this entry
+func (p *program) choose(selection int) error {
+	s := p.waitingSelect()
+	if s == nil {
+		return errors.New("no selection expected")
+	}
+	if selection < 1 || selection > len(s.Selections()) {
+		return fmt.Errorf("selection %d does not exist", selection)
+	}
+	if err := p.goTo(p.pendingSelect + s.Selections()[selection-1].Offset()); err != nil {
+		return err
+	}
+	p.pendingSelect = -1
+	return nil
+}
+
+// goTo moves the program counter to the given block index
+func (p *program) goTo(index int) error {
+	if index < 0 || index >= len(p.tape.Blocks) {
+		return fmt.Errorf("block number %d out of the tape", index+1)
+	}
+	p.pc = index
+	return nil
+}
@@ -0,0 +1,200 @@
+package tape
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProgram(t *testing.T) {
+	tests := []struct {
+		name      string
+		blocks    [][]byte
+		selection int
+		executed  []int
+		err       string
+	}{
+		{
+			name:     "no flow control",
+			blocks:   [][]byte{tzxPause(1), tzxPause(2)},
+			executed: []int{0, 1},
+		},
+		{
+			name: "nested loops",
+			blocks: [][]byte{
+				tzxLoopStart(2), tzxPause(1), tzxLoopStart(2), tzxPause(2), tzxLoopEnd(), tzxLoopEnd(), tzxPause(3),
+			},
+			executed: []int{0, 1, 2, 3, 4, 3, 4, 5, 1, 2, 3, 4, 3, 4, 5, 6},
+		},
+		{
+			name: "jump forward and backward",
+			blocks: [][]byte{
+				tzxJump(3), tzxPause(1), tzxJump(2), tzxJump(-2), tzxPause(2),
+			},
+			executed: []int{0, 3, 1, 2, 4},
+		},
+		{
+			name: "call sequence",
+			blocks: [][]byte{
+				tzxCallSequence(2, 4), tzxJump(5), tzxPause(1), tzxReturnFromSequence(),
+				tzxPause(2), tzxReturnFromSequence(), tzxPause(3),
+			},
+			executed: []int{0, 2, 3, 4, 5, 1, 6},
+		},
+		{
+			name:     "empty call sequence",
+			blocks:   [][]byte{tzxCallSequence(), tzxPause(1)},
+			executed: []int{0, 1},
+		},
+		{
+			name:     "call without return",
+			blocks:   [][]byte{tzxCallSequence(2), tzxPause(1), tzxPause(2)},
+			executed: []int{0, 2},
+		},
+		{
+			name:     "return without call",
+			blocks:   [][]byte{tzxPause(1), tzxReturnFromSequence()},
+			executed: []int{0},
+			err:      "block 2: return from sequence without call sequence",
+		},
+		{
+			name:     "loop end without loop start",
+			blocks:   [][]byte{tzxLoopEnd()},
+			executed: []int{},
+			err:      "block 1: loop end without loop start",
+		},
+		{
+			name:     "jump out of the tape",
+			blocks:   [][]byte{tzxPause(1), tzxJump(5), tzxPause(2)},
+			executed: []int{0},
+			err:      "block 2: block number 7 out of the tape",
+		},
+		{
+			name:     "jump before the tape",
+			blocks:   [][]byte{tzxJump(-1)},
+			executed: []int{},
+			err:      "block 1: block number 0 out of the tape",
+		},
+		{
+			name:     "call out of the tape",
+			blocks:   [][]byte{tzxCallSequence(3), tzxPause(1)},
+			executed: []int{},
+			err:      "block 1: block number 4 out of the tape",
+		},
+		{
+			name:     "infinite jump",
+			blocks:   [][]byte{tzxPause(1), tzxJump(0)},
+			executed: []int{0},
+			err:      "block 2: infinite jump",
+		},
+		{
+			name:     "ignored select",
+			blocks:   [][]byte{tzxSelect(1, 2), tzxPause(1), tzxPause(2)},
+			executed: []int{0, 1, 2},
+		},
+		{
+			name:      "selection",
+			blocks:    [][]byte{tzxSelect(1, 2), tzxPause(1), tzxPause(2)},
+			selection: 2,
+			executed:  []int{0, 2},
+		},
+		{
+			name:      "selection at every select block",
+			blocks:    [][]byte{tzxSelect(2, 3), tzxPause(1), tzxSelect(2, 1), tzxPause(2), tzxPause(3)},
+			selection: 1,
+			executed:  []int{0, 2, 4},
+		},
+		{
+			name:      "undefined selection",
+			blocks:    [][]byte{tzxSelect(1, 2), tzxPause(1), tzxPause(2)},
+			selection: 3,
+			executed:  []int{},
+			err:       "block 1: selection 3 does not exist",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prg := newProgram(newTestTape(t, "test.tzx", tzx(test.blocks...)), test.selection)
+			executed := make([]int, 0)
+			var err error
+			for {
+				var index int
+				var ok bool
+				index, ok, err = prg.next()
+				if err != nil || !ok {
+					break
+				}
+				executed = append(executed, index)
+			}
+
+			if test.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Fatalf("expected error containing %q, got %v", test.err, err)
+			}
+			if !reflect.DeepEqual(executed, test.executed) {
+				t.Errorf("executed blocks %v, expected %v", executed, test.executed)
+			}
+		})
+	}
+}
+
+func TestProgramMaxExecutedBlocks(t *testing.T) {
+	prg := newProgram(newTestTape(t, "test.tzx", tzx(tzxPause(1), tzxJump(-1))), SelectionNone)
+	executedNb := 0
+	for {
+		_, ok, err := prg.next()
+		if err != nil {
+			if !strings.Contains(err.Error(), "never reaches the end of the tape") {
+				t.Fatal(err)
+			}
+			break
+		}
+		if !ok {
+			t.Fatal("the end of the tape is reached")
+		}
+		executedNb++
+	}
+	if executedNb != MaxExecutedBlocks {
+		t.Errorf("%d blocks executed, expected %d", executedNb, MaxExecutedBlocks)
+	}
+}
+
+func TestProgramInteractiveSelect(t *testing.T) {
+	tape := newTestTape(t, "test.tzx", tzx(tzxPause(1), tzxSelect(1, 2), tzxPause(2), tzxPause(3)))
+	prg := newProgram(tape, SelectionInteractive)
+
+	executed := make([]int, 0)
+	next := func() {
+		for {
+			index, ok, err := prg.next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				return
+			}
+			executed = append(executed, index)
+		}
+	}
+
+	next()
+	if !reflect.DeepEqual(executed, []int{0, 1}) || prg.waitingSelect() != tape.Blocks[1] {
+		t.Fatalf("executed blocks %v before the select, expected [0 1] and waiting at block 2", executed)
+	}
+	if err := prg.choose(3); err == nil {
+		t.Error("undefined selection 3 accepted")
+	}
+	if err := prg.choose(2); err != nil {
+		t.Fatal(err)
+	}
+	next()
+	if !reflect.DeepEqual(executed, []int{0, 1, 3}) || prg.waitingSelect() != nil {
+		t.Errorf("executed blocks %v, expected [0 1 3]", executed)
+	}
+	if err := prg.choose(1); err == nil {
+		t.Error("selection accepted without pending select block")
+	}
+}
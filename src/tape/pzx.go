@@ -0,0 +1,117 @@
+package tape
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"io"
+	"os"
+	"strings"
+)
+
+// PzxSignature is the tag of the header block which starts a PZX file
+const PzxSignature = "PZXT"
+
+// PzxTextIds maps the keys of the PZX header texts to the Archive Info text
+// ids. The first text of the header, which has no key, is the title.
+var PzxTextIds = map[string]byte{
+	"Publisher":  0x01,
+	"Author":     0x02,
+	"Year":       0x03,
+	"Language":   0x04,
+	"Type":       0x05,
+	"Price":      0x06,
+	"Protection": 0x07,
+	"Origin":     0x08,
+	"Comment":    0xFF,
+}
+
+// readPzx reads the blocks of a PZX file. The header texts are read as an
+// Archive Info block, browse points as Text Description blocks and stop
+// blocks as "Stop the tape" pauses or "Stop the tape if in 48K mode" blocks.
+func (t *Tape) readPzx(pzxFile *os.File) error {
+	content, err := io.ReadAll(pzxFile)
+	if err != nil {
+		return err
+	}
+
+	if len(content) < 8 || string(content[0:4]) != PzxSignature {
+		return errors.New("not a valid PZX file (no PZXT header block)")
+	}
+
+	for pos := 0; pos < len(content); {
+		if pos+8 > len(content) {
+			return errors.New("not a valid PZX file (truncated block header)")
+		}
+		tag := string(content[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(content[pos+4 : pos+8]))
+		pos += 8
+		if pos+size > len(content) {
+			return errors.New("not a valid PZX file (truncated block)")
+		}
+		data := content[pos : pos+size]
+		pos += size
+
+		var b block.Block
+		switch tag {
+		case "PZXT":
+			if len(data) < 2 {
+				return errors.New("not a valid PZX file (truncated PZXT block)")
+			}
+			t.Header = Header{
+				MajorVersion: int(data[0]),
+				MinorVersion: int(data[1]),
+			}
+			if texts := pzxTexts(data[2:]); len(texts) > 0 {
+				b = block.NewArchiveInfo(texts)
+			}
+		case "PULS":
+			b, err = block.NewPzxPulseSequence(data)
+		case "DATA":
+			b, err = block.NewPzxDataBlock(data)
+		case "PAUS":
+			b, err = block.NewPzxPause(data)
+		case "BRWS":
+			b = block.NewTextDescription(strings.TrimRight(string(data), "\x00"))
+		case "STOP":
+			if len(data) >= 2 && binary.LittleEndian.Uint16(data[0:2]) == 1 {
+				b = &block.StopTape48K{}
+			} else {
+				b = block.NewPause(0)
+			}
+		}
+		// Unknown blocks are skipped, as required by the PZX specification
+		if err != nil {
+			return err
+		}
+		if b != nil {
+			t.Blocks = append(t.Blocks, b)
+		}
+	}
+
+	return nil
+}
+
+// pzxTexts returns the Archive Info texts of the texts of a PZX header: the
+// title followed by key and value pairs, all null terminated. Keys not listed
+// in PzxTextIds are kept as comments.
+func pzxTexts(data []byte) []block.Text {
+	texts := make([]block.Text, 0)
+	if len(data) == 0 {
+		return texts
+	}
+
+	strs := strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+	if strs[0] != "" {
+		texts = append(texts, block.NewText(0x00, strs[0]))
+	}
+	for i := 1; i+1 < len(strs); i += 2 {
+		if id, ok := PzxTextIds[strs[i]]; ok {
+			texts = append(texts, block.NewText(id, strs[i+1]))
+		} else {
+			texts = append(texts, block.NewText(0xFF, strs[i]+": "+strs[i+1]))
+		}
+	}
+
+	return texts
+}
@@ -0,0 +1,196 @@
+package tape
+
+import (
+	"encoding/binary"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// pzxBlock returns a PZX block with the given tag and content
+func pzxBlock(tag string, data []byte) []byte {
+	b := binary.LittleEndian.AppendUint32([]byte(tag), uint32(len(data)))
+	return append(b, data...)
+}
+
+// pzx returns the content of a PZX file made of the given blocks
+func pzx(blocks ...[]byte) []byte {
+	content := make([]byte, 0)
+	for _, b := range blocks {
+		content = append(content, b...)
+	}
+	return content
+}
+
+func TestReadPzx(t *testing.T) {
+	header := pzxBlock("PZXT", []byte("\x01\x00Title\x00Author\x00Someone\x00"))
+	puls := pzxBlock("PULS", []byte{0x03, 0x80, 0xe8, 0x03, 0x00, 0x80, 0x01, 0x00, 0xf4, 0x01})
+
+	tests := []struct {
+		name    string
+		content []byte
+		blocks  []string
+		err     string
+	}{
+		{"header only", pzx(pzxBlock("PZXT", []byte{1, 0})), []string{}, ""},
+		{"header texts", pzx(header), []string{"Archive Info"}, ""},
+		{
+			name: "all blocks",
+			content: pzx(
+				header,
+				puls,
+				pzxBlock("DATA", []byte{0x10, 0, 0, 0, 0, 0, 2, 2, 0x57, 0x03, 0x57, 0x03, 0xae, 0x06, 0xae, 0x06, 0xff, 0x01}),
+				pzxBlock("PAUS", []byte{0x10, 0x27, 0, 0}),
+				pzxBlock("BRWS", []byte("Level 1")),
+				pzxBlock("XXXX", []byte{1, 2, 3}),
+				pzxBlock("STOP", []byte{1, 0}),
+				pzxBlock("STOP", []byte{0, 0}),
+			),
+			blocks: []string{"Archive Info", "PZX Pulse Sequence", "PZX Data Block", "PZX Pause", "Text Description", "Stop the tape if in 48K mode", "Pause (silence)"},
+		},
+		{"no header", pzx(puls), nil, "not a valid PZX file (no PZXT header block)"},
+		{"truncated block header", pzx(header, []byte("PULS")), nil, "not a valid PZX file (truncated block header)"},
+		{"truncated block", pzx(header, puls[:len(puls)-1]), nil, "not a valid PZX file (truncated block)"},
+		{"truncated header block", pzx(pzxBlock("PZXT", []byte{1})), nil, "not a valid PZX file (truncated PZXT block)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tape, ok := readTestTape(t, "test.pzx", test.content, test.err)
+			if !ok {
+				return
+			}
+			if tape.Format != FormatPzx {
+				t.Errorf("format %s", tape.Format)
+			}
+			if tape.Header.MajorVersion != 1 {
+				t.Errorf("version %d.%d", tape.Header.MajorVersion, tape.Header.MinorVersion)
+			}
+			if names := blockNames(tape); !reflect.DeepEqual(names, test.blocks) {
+				t.Fatalf("blocks %v, expected %v", names, test.blocks)
+			}
+		})
+	}
+}
+
+func TestPzxPulses(t *testing.T) {
+	tape := newTestTape(t, "test.pzx", pzx(
+		pzxBlock("PZXT", []byte{1, 0}),
+		// 3 pulses of 1000, 1 of 65536 and 1 of 500 T-states
+		pzxBlock("PULS", []byte{0x03, 0x80, 0xe8, 0x03, 0x01, 0x80, 0x01, 0x80, 0x00, 0x00, 0xf4, 0x01}),
+		// 9 bits of data 0xff 0x00, with an initial high level
+		pzxBlock("DATA", []byte{0x09, 0, 0, 0x80, 0, 0, 2, 2, 0x57, 0x03, 0x57, 0x03, 0xae, 0x06, 0xae, 0x06, 0xff, 0x00}),
+		pzxBlock("PAUS", []byte{0xb0, 0x35, 0, 0}),
+	))
+
+	expected := [][]int{
+		{1000, 1000, 1000, 65536, 500},
+		{1710, 1710, 1710, 1710, 1710, 1710, 1710, 1710, 1710, 1710, 1710, 1710, 1710, 1710, 1710, 1710, 855, 855},
+		{13744},
+	}
+	for i, lengths := range expected {
+		pulses := tape.Blocks[i].Pulses()
+		if len(pulses) != len(lengths) {
+			t.Fatalf("block %d: %d pulses, expected %d", i+1, len(pulses), len(lengths))
+		}
+		for j, length := range lengths {
+			if pulses[j].Length != length {
+				t.Errorf("block %d pulse %d: %d T-states, expected %d", i+1, j+1, pulses[j].Length, length)
+			}
+		}
+	}
+	if edge := tape.Blocks[1].Pulses()[0].Edge; edge != block.EdgeHigh {
+		t.Errorf("first data pulse edge %d, expected high", edge)
+	}
+	if edge := tape.Blocks[2].Pulses()[0].Edge; edge != block.EdgeLow {
+		t.Errorf("pause edge %d, expected low", edge)
+	}
+}
+
+// renderSamples returns all the samples of the given tape
+func renderSamples(t *testing.T, tape *Tape) []byte {
+	t.Helper()
+	r, err := NewReader(tape, ReaderOptions{SamplingRate: 44100, BitDepth: 8, SpeedFactor: 1, Selection: SelectionNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return samples
+}
+
+func TestPzxRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks [][]byte
+	}{
+		{"pure tone", [][]byte{tzxPureTone(2168, 101), tzxPause(100)}},
+		{"standard speed data", [][]byte{{0x10, 0xe8, 0x03, 3, 0, 0x00, 0x5a, 0xa5}, {0x10, 0, 0, 2, 0, 0xff, 0x01}}},
+		{"pure data", [][]byte{tzxPureTone(2168, 100), {0x14, 0x57, 0x03, 0xae, 0x06, 3, 0xe8, 0x03, 2, 0, 0, 0xf0, 0xa0}}},
+		{"set signal level", [][]byte{tzxPureTone(1000, 3), tzxSetSignalLevel(true), tzxPureTone(1000, 3), tzxSetSignalLevel(false), tzxPause(10)}},
+		{"generalized data", [][]byte{tzxPause(10), tzxGeneralizedDataBlock(0x03, 1000, 5), tzxGeneralizedDataBlock(0x01, 1000, 5), tzxPause(10)}},
+		{"long pulse", [][]byte{{0x13, 2, 0xff, 0xff, 0xff, 0xff}, tzxPureTone(40000, 2)}},
+		{"texts and stops", [][]byte{
+			{0x32, 9, 0, 1, 0x00, 6, 'T', 'i', 't', 'l', 'e', '!'},
+			{0x21, 4, 'G', 'a', 'm', 'e'},
+			tzxPureTone(2168, 10),
+			{0x22},
+			{0x30, 4, 'S', 'i', 'd', 'e'},
+			{0x2A, 0, 0, 0, 0},
+			tzxPureTone(2168, 10),
+			tzxPause(0),
+			tzxPureTone(2168, 10),
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tzxTape := newTestTape(t, "test.tzx", tzx(test.blocks...))
+			data, err := writePzx(tzxTape, ReaderOptions{Selection: SelectionNone})
+			if err != nil {
+				t.Fatal(err)
+			}
+			pzxTape := newTestTape(t, "test.pzx", data)
+
+			tzxSamples := renderSamples(t, tzxTape)
+			pzxSamples := renderSamples(t, pzxTape)
+			if len(tzxSamples) != len(pzxSamples) {
+				t.Fatalf("%d samples read back, expected %d", len(pzxSamples), len(tzxSamples))
+			}
+			for i := range tzxSamples {
+				if tzxSamples[i] != pzxSamples[i] {
+					t.Fatalf("sample %d differs", i)
+				}
+			}
+
+			// Blocks read from a PZX file are written back as is
+			rewritten, err := writePzx(pzxTape, ReaderOptions{Selection: SelectionNone})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(rewritten) != string(data) {
+				t.Error("PZX file differs when written again")
+			}
+		})
+	}
+}
+
+func TestPzxInfoWithoutBlockId(t *testing.T) {
+	tape := newTestTape(t, "test.pzx", pzx(
+		pzxBlock("PZXT", []byte{1, 0}),
+		pzxBlock("PAUS", []byte{0x10, 0x27, 0, 0}),
+	))
+	for _, row := range tape.Info().Blocks[0] {
+		if row[0] == "Block ID" {
+			t.Errorf("block ID %s of a PZX block", row[1])
+		}
+	}
+
+	tape = newTestTape(t, "test.tzx", tzx(tzxPause(10)))
+	if row := tape.Info().Blocks[0][1]; row[0] != "Block ID" || row[1] != "20" {
+		t.Errorf("info row %v, expected the block ID of a TZX block", row)
+	}
+}
@@ -0,0 +1,274 @@
+package tape
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/TiBeN/tzx-player/tape/block"
+)
+
+// PZX version of the written files
+const PzxMajorVersion = 1
+const PzxMinorVersion = 0
+
+// pzxMaxPulseLength is the longest pulse a PZX Pulse Sequence block can hold
+const pzxMaxPulseLength = 0x7fffffff
+
+// pzxWriter writes a tape as a PZX file. The blocks are written in the order
+// given by the tape program. Data blocks are written as PZX data blocks, the
+// other pulses as PZX pulse sequences. As pulses levels are absolute in PZX,
+// the writer follows the level of the signal like the Reader does.
+type pzxWriter struct {
+	options   ReaderOptions
+	out       bytes.Buffer
+	level     bool
+	pulseOpen bool
+
+	// pulses are the lengths of the pulses of the pulse sequence being
+	// written. The first pulse is low, then the level toggles at each pulse.
+	pulses []int
+}
+
+// writePzx returns the content of the PZX file of the given tape. Only the
+// Selection, VideoStandard, CompatibilityTail, MinPilotPulses and
+// MaxPauseDuration options are used.
+func writePzx(tape *Tape, options ReaderOptions) ([]byte, error) {
+	tape = tape.withVideoStandard(options.VideoStandard)
+	w := &pzxWriter{options: options}
+	w.writeHeader(tape)
+
+	program := newProgram(tape, options.Selection)
+	for {
+		index, ok, err := program.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		w.writeTapeBlock(tape.Blocks[index])
+	}
+	w.flushPulses()
+
+	return w.out.Bytes(), nil
+}
+
+// writeHeader writes the PZXT block. Its texts are the ones of the first
+// Archive Info block of the tape.
+func (w *pzxWriter) writeHeader(tape *Tape) {
+	data := []byte{PzxMajorVersion, PzxMinorVersion}
+
+	for _, b := range tape.Blocks {
+		archiveInfo, ok := b.(*block.ArchiveInfo)
+		if !ok {
+			continue
+		}
+		title := ""
+		pairs := make([]string, 0)
+		for _, text := range archiveInfo.Texts() {
+			if text.TextId() == 0x00 {
+				title = text.Text()
+				continue
+			}
+			for key, id := range PzxTextIds {
+				if id == text.TextId() {
+					pairs = append(pairs, key, text.Text())
+				}
+			}
+		}
+		for _, s := range append([]string{title}, pairs...) {
+			data = append(data, s...)
+			data = append(data, 0)
+		}
+		break
+	}
+
+	w.writeBlock("PZXT", data)
+}
+
+// writeTapeBlock writes the given tape block
+func (w *pzxWriter) writeTapeBlock(b block.Block) {
+	switch b := b.(type) {
+	case *block.ArchiveInfo:
+		// Written in the header
+		return
+	case *block.TextDescription:
+		w.writeBrowsePoint(b.Description())
+		return
+	case *block.GroupStart:
+		w.writeBrowsePoint(b.GroupName())
+		return
+	case *block.StopTape48K:
+		w.writeStop(1)
+		return
+	case *block.Pause:
+		if b.StopsTheTape() {
+			w.writeStop(0)
+			return
+		}
+	}
+
+	if p, ok := b.(block.PzxBlock); ok {
+		// Blocks read from a PZX file are written as is
+		w.flushPulses()
+		w.writeBlock(p.PzxContent())
+		for _, pulse := range b.Pulses() {
+			w.level, w.pulseOpen = pulse.Level(w.level, w.pulseOpen)
+		}
+		if s, ok := b.(block.Silence); ok && s.EndsWithSilence() {
+			w.pulseOpen = false
+		}
+		return
+	}
+
+	pulses := b.Pulses()
+	if p, ok := b.(block.PilotTone); ok && w.options.MinPilotPulses > 0 {
+		pulses = shortenPilotTone(pulses, p.PilotPulsesNb(), w.options.MinPilotPulses)
+	}
+	if d, ok := b.(block.DataBits); ok {
+		data, bitsNb, zeroPulseLength, onePulseLength := d.DataBits()
+		if bitsNb > 0 && bitsNb*2 <= len(pulses) {
+			w.addPulses(pulses[:len(pulses)-bitsNb*2])
+			w.writeData(data, bitsNb, zeroPulseLength, onePulseLength)
+			pulses = nil
+		}
+	}
+	w.addPulses(pulses)
+	if t, ok := b.(block.CompatibilityTail); ok && w.options.CompatibilityTail {
+		w.addPulses(t.TailPulses())
+	}
+	if s, ok := b.(block.Silence); ok && s.EndsWithSilence() {
+		w.pulseOpen = false
+	}
+
+	pauseDuration := b.PauseDuration()
+	if w.options.MaxPauseDuration > 0 && pauseDuration > w.options.MaxPauseDuration {
+		pauseDuration = w.options.MaxPauseDuration
+	}
+	w.addPause(pauseDuration)
+}
+
+// addPulses adds the given pulses to the pulse sequence being written
+func (w *pzxWriter) addPulses(pulses []block.Pulse) {
+	for _, pulse := range pulses {
+		w.level, w.pulseOpen = pulse.Level(w.level, w.pulseOpen)
+		w.addLevel(w.level, pulse.Length)
+	}
+}
+
+// addLevel adds the given level held during length T-states to the pulse
+// sequence being written
+func (w *pzxWriter) addLevel(level bool, length int) {
+	if length == 0 {
+		return
+	}
+	if len(w.pulses) == 0 && level {
+		w.pulses = append(w.pulses, 0)
+	}
+	last := len(w.pulses) - 1
+	if last >= 0 && (last%2 == 1) == level {
+		length += w.pulses[last]
+		w.pulses = w.pulses[:last]
+	}
+	for length > pzxMaxPulseLength {
+		w.pulses = append(w.pulses, pzxMaxPulseLength, 0)
+		length -= pzxMaxPulseLength
+	}
+	w.pulses = append(w.pulses, length)
+}
+
+// addPause adds a pause of the given duration in ms, following the TZX rule:
+// the last pulse is finished by 1 ms at the opposite level, then the level
+// goes low
+func (w *pzxWriter) addPause(duration int) {
+	if duration == 0 {
+		return
+	}
+	tStatesPerMs := block.ZXClockHz / 1000
+	if w.pulseOpen {
+		w.level = !w.level
+		w.addLevel(w.level, tStatesPerMs)
+		duration--
+		w.pulseOpen = false
+	}
+	w.level = false
+	if duration > 0 {
+		w.flushPulses()
+		data := make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, uint32(duration*tStatesPerMs))
+		w.writeBlock("PAUS", data)
+	}
+}
+
+// writeData writes a PZX data block of bits encoded by two pulses. The first
+// pulse toggles the current level, unless no edge is pending.
+func (w *pzxWriter) writeData(data []byte, bitsNb int, zeroPulseLength int, onePulseLength int) {
+	w.flushPulses()
+
+	initialLevel, _ := block.Pulse{Length: zeroPulseLength}.Level(w.level, w.pulseOpen)
+	count := uint32(bitsNb)
+	if initialLevel {
+		count |= 0x80000000
+	}
+
+	content := binary.LittleEndian.AppendUint32(nil, count)
+	content = binary.LittleEndian.AppendUint16(content, 0)
+	content = append(content, 2, 2)
+	for _, length := range []int{zeroPulseLength, zeroPulseLength, onePulseLength, onePulseLength} {
+		content = binary.LittleEndian.AppendUint16(content, uint16(length))
+	}
+	content = append(content, data[:(bitsNb+7)/8]...)
+	w.writeBlock("DATA", content)
+
+	// The level toggles at each of the two pulses of the bits
+	w.level = !initialLevel
+	w.pulseOpen = true
+}
+
+// writeBrowsePoint writes a PZX browse point with the given text
+func (w *pzxWriter) writeBrowsePoint(text string) {
+	w.flushPulses()
+	w.writeBlock("BRWS", []byte(text))
+}
+
+// writeStop writes a PZX stop block. flags is 1 to stop only in 48K mode, 0
+// to always stop.
+func (w *pzxWriter) writeStop(flags uint16) {
+	w.flushPulses()
+	w.writeBlock("STOP", binary.LittleEndian.AppendUint16(nil, flags))
+}
+
+// flushPulses writes the pulse sequence being written, if any
+func (w *pzxWriter) flushPulses() {
+	if len(w.pulses) == 0 {
+		return
+	}
+
+	data := make([]byte, 0)
+	for i := 0; i < len(w.pulses); {
+		length := w.pulses[i]
+		count := 1
+		for i+count < len(w.pulses) && w.pulses[i+count] == length && count < 0x7fff {
+			count++
+		}
+		if count > 1 {
+			data = binary.LittleEndian.AppendUint16(data, uint16(0x8000|count))
+		}
+		if length >= 0x8000 {
+			data = binary.LittleEndian.AppendUint16(data, uint16(0x8000|length>>16))
+			data = binary.LittleEndian.AppendUint16(data, uint16(length&0xffff))
+		} else {
+			data = binary.LittleEndian.AppendUint16(data, uint16(length))
+		}
+		i += count
+	}
+	w.pulses = nil
+
+	w.writeBlock("PULS", data)
+}
+
+// writeBlock writes a PZX block with the given tag and content
+func (w *pzxWriter) writeBlock(tag string, data []byte) {
+	w.out.WriteString(tag)
+	_ = binary.Write(&w.out, binary.LittleEndian, uint32(len(data)))
+	w.out.Write(data)
+}
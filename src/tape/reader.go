@@ -2,68 +2,249 @@ package tape
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"github.com/TiBeN/tzx-player/tape/block"
+	"io"
 	"math"
+	"sort"
 )
 
-const TStatePerSecond = 1.0 / 3500000
+// LeadingSilenceDuration is the duration in ms of the silence added at the
+// beginning of the tape to prevent players to start too abruptly
+const LeadingSilenceDuration = 500
+
+// TrailingSilenceDuration is the duration in ms of the silence added at the
+// end of the tape to prevent players to stop too abruptly
+const TrailingSilenceDuration = 500
+
+// FloatBitDepth is the bit depth of 32 bit floating point samples. Other bit
+// depths are integer samples.
+const FloatBitDepth = 32
 
 var AllowedBitDepths []int
 
+// Machine is the model of the computer the tape is played to
+type Machine int
+
+const (
+	Machine128K Machine = iota
+	Machine48K
+)
+
 func init() {
-	AllowedBitDepths = []int{8, 16}
+	AllowedBitDepths = []int{8, 16, 24, FloatBitDepth}
 }
 
 // Reader is a TZX tape PCM audio sample io.Reader implementation.
-// Its converts block pulse to PCM audio samples
+// Its converts block pulse to PCM audio samples. Samples are little endian
+// unsigned 8 bit, signed 16 or 24 bit integers, or 32 bit floats.
+// Samples are rendered lazily, one block at a time, when they are read. The
+// position of each block in the samples stream is computed when the reader is
+// created, which allows seeking without rendering the whole tape.
 type Reader struct {
 	tape         *Tape
-	samples      *bytes.Reader
+	program      *program
 	SamplingRate int
 	bitDepth     int
 	speedFactor  float64
+	machine      Machine
+	tail         bool
+	pos          int64
+	size         int64
 	blocksBytes  []BlockByte
+	blocksEnd    int64
+	endState     renderState
+	stopsBytes   []int64
+	segment      []byte
+	segmentStart int64
+	bandLimited  bool
+	corrections  map[int64]float64
+	minPilot     int
+	maxPause     int
+	windowStart  int64
+	windowEnd    int64
 }
 
+// ReaderOptions holds the parameters of the audio samples generation
+type ReaderOptions struct {
+	SamplingRate int
+	BitDepth     int
+	SpeedFactor  float64
+
+	// Selection is the entry (starting from 1) to choose at Select blocks.
+	// SelectionInteractive stops the generation at Select blocks until
+	// Reader.Select is called. SelectionNone ignores them.
+	Selection int
+
+	// Machine decides whether "Stop the tape if in 48K mode" blocks stop the tape
+	Machine Machine
+
+	// VideoStandard overrides the video standard of C64 TAP files
+	VideoStandard VideoStandard
+
+	// CompatibilityTail appends the non-standard tail of pulses after the data
+	// blocks which define one
+	CompatibilityTail bool
+
+	// BandLimited smooths the edges of the signal according to their position
+	// between two samples, which reduces aliasing at low sampling rates
+	BandLimited bool
+
+	// MinPilotPulses shortens the pilot tones of the data blocks to this number
+	// of pulses. Sync and data pulses are left untouched. 0 keeps the pilot tones.
+	MinPilotPulses int
+
+	// MaxPauseDuration limits the pauses to this duration in ms. Pauses which
+	// stop the tape are kept. 0 keeps the pauses.
+	MaxPauseDuration int
+
+	// From and To limit the samples stream to a window of the tape. Positions
+	// of the stream stay relative to the whole tape. nil means the beginning
+	// or the end of the tape.
+	From *Position
+	To   *Position
+}
+
+// BlockByte is an entry of the block position table. Entries are stored in
+// the order the blocks are played, which may differ from the tape order when
+// the tape contains flow control blocks.
 type BlockByte struct {
-	blockByte int64
-	blockName string
+	blockByte  int64
+	blockIndex int
+	blockName  string
+	state      renderState
 }
 
-func NewReader(tape *Tape, samplingRate int, bitDepth int, speedFactor float64) (*Reader, error) {
+// renderState is the signal state carried from block to block by the rendering.
+// pulseOpen tells if the last pulse is finished by an edge, which is not the
+// case after a pause. remainder is the fraction of sample elapsed but not
+// rendered yet, which keeps the rendered duration equal to the duration of the
+// pulses.
+type renderState struct {
+	level     bool
+	pulseOpen bool
+	remainder float64
+}
+
+// startState is the render state at the beginning of the stream. Samples are
+// centered on their duration, so edges land on the nearest sample and each
+// duration renders as its rounded number of samples.
+var startState = renderState{remainder: 0.5}
+
+// samplesEpsilon absorbs the floating point error of the number of samples,
+// which would otherwise drop a sample at exact boundaries
+const samplesEpsilon = 1e-6
+
+// sampleWriter receives the rendered samples: nbSamples samples of the given
+// level, starting at the given fraction of the first sample
+type sampleWriter func(nbSamples int, level bool, offset float64)
+
+func NewReader(tape *Tape, options ReaderOptions) (*Reader, error) {
 	bitDepthAllowed := false
 	for _, b := range AllowedBitDepths {
-		if b == bitDepth {
+		if b == options.BitDepth {
 			bitDepthAllowed = true
 			break
 		}
 	}
 
 	if !bitDepthAllowed {
-		return nil, fmt.Errorf("unsupported bit depth '%d'", bitDepth)
+		return nil, fmt.Errorf("unsupported bit depth '%d'", options.BitDepth)
 	}
 
+	tape = tape.withVideoStandard(options.VideoStandard)
+
 	r := &Reader{
 		tape:         tape,
-		SamplingRate: samplingRate,
-		bitDepth:     bitDepth,
-		speedFactor:  speedFactor,
+		program:      newProgram(tape, options.Selection),
+		SamplingRate: options.SamplingRate,
+		bitDepth:     options.BitDepth,
+		speedFactor:  options.SpeedFactor,
+		machine:      options.Machine,
+		tail:         options.CompatibilityTail,
+		bandLimited:  options.BandLimited,
+		minPilot:     options.MinPilotPulses,
+		maxPause:     options.MaxPauseDuration,
+		corrections:  make(map[int64]float64),
+		endState:     startState,
+		windowEnd:    -1,
+	}
+
+	// Add some silence at the beginning to prevent players to start too abruptly
+	nbSamples := 0
+	r.renderPause(LeadingSilenceDuration, &r.endState, func(n int, _ bool, _ float64) {
+		nbSamples += n
+	})
+	r.blocksEnd = r.samplesSize(nbSamples)
+
+	if err := r.indexBlocks(); err != nil {
+		return nil, err
+	}
+
+	if options.From != nil {
+		start, err := r.resolvePosition(*options.From, false)
+		if err != nil {
+			return nil, err
+		}
+		r.windowStart = start
+		r.pos = start
 	}
-	r.generateSamples()
+	if options.To != nil {
+		end, err := r.resolvePosition(*options.To, true)
+		if err != nil {
+			return nil, err
+		}
+		if end <= r.windowStart {
+			return nil, fmt.Errorf("end position '%s' is before the start position", options.To)
+		}
+		r.windowEnd = end
+	}
+
 	return r, nil
 }
 
 func (r *Reader) Read(p []byte) (n int, err error) {
-	return r.samples.Read(p)
+	if r.pos >= r.Size() {
+		return 0, io.EOF
+	}
+	n = r.readAt(p, r.pos)
+	r.pos += int64(n)
+	return n, nil
 }
 
+// readAt reads the samples starting at the given position, without changing
+// the position of the reader. It returns the number of bytes read, which is
+// less than the size of the buffer at the end of the stream.
+func (r *Reader) readAt(p []byte, pos int64) (n int) {
+	end := r.Size()
+	for n < len(p) && pos < end {
+		if r.segment == nil || pos < r.segmentStart || pos >= r.segmentStart+int64(len(r.segment)) {
+			r.renderSegment(pos)
+		}
+		segment := r.segment[pos-r.segmentStart:]
+		if int64(len(segment)) > end-pos {
+			segment = segment[:end-pos]
+		}
+		copied := copy(p[n:], segment)
+		n += copied
+		pos += int64(copied)
+	}
+	return n
+}
+
+// Size returns the position of the end of the samples stream, which is the
+// end of the window when the stream is limited to a window of the tape
 func (r *Reader) Size() int64 {
-	return r.samples.Size()
+	if r.windowEnd >= 0 && r.windowEnd < r.size {
+		return r.windowEnd
+	}
+	return r.size
 }
 
 func (r *Reader) Pos() int64 {
-	return r.samples.Size() - int64(r.samples.Len())
+	return r.pos
 }
 
 func (r *Reader) PosPercent() int64 {
@@ -84,78 +265,421 @@ func (r *Reader) FileName() string {
 
 // @TODO: return the block id
 func (r *Reader) BlockInfo() string {
-	currentByteNb := r.Pos()
-	blockInfo := ""
-	for i, b := range r.blocksBytes {
-		if i >= len(r.blocksBytes)-1 || currentByteNb <= r.blocksBytes[i+1].blockByte {
-			blockInfo = fmt.Sprintf("%d/%d - %s", i+1, len(r.blocksBytes), b.blockName)
-			break
-		}
+	if len(r.blocksBytes) == 0 {
+		return ""
 	}
-	return blockInfo
+	b, ok := r.entryAt(r.Pos())
+	if !ok {
+		b = r.blocksBytes[0]
+	}
+	return fmt.Sprintf("%d/%d - %s", b.blockIndex+1, len(r.tape.Blocks), b.blockName)
 }
 
 func (r *Reader) Seek(offset int64, whence int) (int64, error) {
-	return r.samples.Seek(offset, whence)
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = r.pos + offset
+	case io.SeekEnd:
+		pos = r.Size() + offset
+	default:
+		return 0, errors.New("tape.Reader.Seek: invalid whence")
+	}
+	if pos < 0 {
+		return 0, errors.New("tape.Reader.Seek: negative position")
+	}
+
+	// Keep reading whole samples, inside the window
+	pos -= pos % r.sampleSize()
+	if pos < r.windowStart {
+		pos = r.windowStart
+	}
+	r.pos = pos
+	return pos, nil
 }
 
-func (r *Reader) generateSamples() {
-	samples := make([]byte, 0)
+// entryIndexAt returns the index in the block position table of the block
+// being played at the given position, or -1 before the first block
+func (r *Reader) entryIndexAt(pos int64) int {
+	return sort.Search(len(r.blocksBytes), func(i int) bool {
+		return r.blocksBytes[i].blockByte > pos
+	}) - 1
+}
 
-	// Add some silence at the beginning to prevent players to start too abruptly
-	samples = append(samples, r.pauseToSamples(500)...)
+// firstEntryIndexFrom returns the index in the block position table of the
+// first block starting at or after the given position
+func (r *Reader) firstEntryIndexFrom(pos int64) int {
+	return sort.Search(len(r.blocksBytes), func(i int) bool {
+		return r.blocksBytes[i].blockByte >= pos
+	})
+}
+
+// entryAt returns the block position table entry of the block being played at
+// the given position
+func (r *Reader) entryAt(pos int64) (BlockByte, bool) {
+	i := r.entryIndexAt(pos)
+	if i < 0 {
+		return BlockByte{}, false
+	}
+	return r.blocksBytes[i], true
+}
+
+// NextStop returns the position of the next "Stop the tape" point after the
+// current position, or -1 if there is none
+func (r *Reader) NextStop() int64 {
+	pos := r.Pos()
+	for _, stop := range r.stopsBytes {
+		if stop > pos {
+			return stop
+		}
+	}
+	return -1
+}
+
+// stopsTheTape tells if the playing must be stopped when the given block is reached
+func (r *Reader) stopsTheTape(b block.Block) bool {
+	switch b := b.(type) {
+	case *block.Pause:
+		return b.StopsTheTape()
+	case *block.StopTape48K:
+		return r.machine == Machine48K
+	}
+	return false
+}
+
+// PendingSelect returns the Select block at which the samples generation
+// waits for a choice, or nil. Samples following this block are available once
+// Reader.Select has been called.
+func (r *Reader) PendingSelect() *block.Select {
+	if r.windowEnd >= 0 && r.windowEnd < r.size {
+		return nil
+	}
+	return r.program.waitingSelect()
+}
+
+// Select chooses the entry (starting from 1) of the pending Select block then
+// indexes the blocks following this entry
+func (r *Reader) Select(selection int) error {
+	if err := r.program.choose(selection); err != nil {
+		return err
+	}
+	return r.indexBlocks()
+}
 
-	for _, b := range r.tape.Blocks {
-		r.blocksBytes = append(r.blocksBytes, BlockByte{blockByte: int64(len(samples)), blockName: b.Name()})
-		samples = append(samples, r.pulsesToSamples(b.Pulses())...)
-		samples = append(samples, r.pauseToSamples(b.PauseDuration())...)
+// indexBlocks computes the position of the blocks given by the tape program in
+// the samples stream, until the end of the tape or a Select block waiting for a
+// choice. Only the number of samples is computed, samples are not rendered.
+func (r *Reader) indexBlocks() error {
+	// Edges may smooth samples of the neighbour blocks when the rendering is
+	// band limited. These corrections are recorded to be applied when the
+	// neighbour blocks are rendered.
+	var nbSamples int
+	var start int64
+	var level bool
+	var pending []sampleCorrection
+	countSamples := func(n int, l bool, offset float64) {
+		if r.bandLimited && l != level {
+			pos := start + int64(nbSamples)
+			before, after := edgeCorrections(level, l, offset)
+			if nbSamples == 0 && pos > 0 {
+				r.corrections[pos-1] += before
+			}
+			pending = append(pending, sampleCorrection{pos, after})
+		}
+		if n > 0 {
+			pending = pending[:0]
+		}
+		level = l
+		nbSamples += n
+	}
+	endBlock := func() {
+		for _, c := range pending {
+			r.corrections[c.pos] += c.value
+		}
+		pending = pending[:0]
+	}
+
+	for {
+		i, ok, err := r.program.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		b := r.tape.Blocks[i]
+		r.blocksBytes = append(r.blocksBytes, BlockByte{
+			blockByte:  r.blocksEnd,
+			blockIndex: i,
+			blockName:  b.Name(),
+			state:      r.endState,
+		})
+		if r.stopsTheTape(b) {
+			r.stopsBytes = append(r.stopsBytes, r.blocksEnd)
+		}
+		nbSamples = 0
+		start = r.blocksEnd / r.sampleSize()
+		level = r.endState.level
+		r.renderBlock(b, &r.endState, countSamples)
+		endBlock()
+		r.blocksEnd += r.samplesSize(nbSamples)
 	}
 
 	// Add some silence in the end to prevent players to stop too abruptly
-	samples = append(samples, r.pauseToSamples(500)...)
+	r.size = r.blocksEnd
+	if r.program.waitingSelect() == nil {
+		nbSamples = 0
+		start = r.blocksEnd / r.sampleSize()
+		level = r.endState.level
+		state := r.endState
+		r.renderPause(TrailingSilenceDuration, &state, countSamples)
+		r.size += r.samplesSize(nbSamples)
+	}
 
-	r.samples = bytes.NewReader(samples)
+	// The rendered segment may be the trailing silence, which moved
+	r.segment = nil
+
+	return nil
 }
 
-// pulsesToSamples encodes the given pulses as audio PCM samples.
-func (r *Reader) pulsesToSamples(pulses []block.Pulse) []byte {
-	samples := make([]byte, 0)
+// renderSegment renders the samples of the segment of the stream containing
+// the given position. A segment is a block, or the leading or trailing silence.
+func (r *Reader) renderSegment(pos int64) {
+	r.segment = r.segment[:0]
+	var level bool
+	corrections := make(map[int64]float64)
+	writeSamples := func(n int, l bool, offset float64) {
+		if r.bandLimited && l != level {
+			pos := int64(len(r.segment)) / r.sampleSize()
+			before, after := edgeCorrections(level, l, offset)
+			if pos > 0 {
+				corrections[pos-1] += before
+			}
+			corrections[pos] += after
+		}
+		level = l
+		sample := r.sampleValue(l)
+		for i := 0; i < n; i++ {
+			r.segment = append(r.segment, sample...)
+		}
+	}
 
-	for _, pulse := range pulses {
-		nbSamples := int(math.Ceil(((TStatePerSecond * r.speedFactor) / (1.0 / float64(r.SamplingRate))) * float64(pulse.Length)))
-		pulseSamples := make([]byte, 0)
-		for i := 0; i < nbSamples; i++ {
-			pulseSamples = append(pulseSamples, r.sampleValue(pulse.Level)...)
+	if len(r.blocksBytes) == 0 || pos < r.blocksBytes[0].blockByte {
+		r.segmentStart = 0
+		state := renderState{}
+		r.renderPause(LeadingSilenceDuration, &state, writeSamples)
+	} else if pos >= r.blocksEnd {
+		r.segmentStart = r.blocksEnd
+		state := r.endState
+		level = state.level
+		r.renderPause(TrailingSilenceDuration, &state, writeSamples)
+	} else {
+		// Find the last block starting before the position. Previous blocks
+		// starting at the same position have no samples.
+		entry := r.blocksBytes[r.entryIndexAt(pos)]
+		r.segmentStart = entry.blockByte
+		state := entry.state
+		level = state.level
+		r.renderBlock(r.tape.Blocks[entry.blockIndex], &state, writeSamples)
+	}
+
+	if r.bandLimited {
+		r.applyCorrections(corrections)
+	}
+}
+
+// applyCorrections smooths the samples of the rendered segment. Corrections
+// are given by sample index from the start of the segment. Corrections of the
+// samples of the neighbour segments are ignored, they are recorded by the
+// indexing instead.
+func (r *Reader) applyCorrections(corrections map[int64]float64) {
+	sampleSize := r.sampleSize()
+	nbSamples := int64(len(r.segment)) / sampleSize
+	if nbSamples == 0 {
+		return
+	}
+	start := r.segmentStart / sampleSize
+	corrections[0] += r.corrections[start]
+	if nbSamples > 1 {
+		corrections[nbSamples-1] += r.corrections[start+nbSamples-1]
+	}
+
+	for i, correction := range corrections {
+		if i < 0 || i >= nbSamples || correction == 0 {
+			continue
+		}
+		sample := r.segment[i*sampleSize : (i+1)*sampleSize]
+		amplitude := correction
+		if bytes.Equal(sample, r.sampleValue(true)) {
+			amplitude += 1
 		}
-		samples = append(samples, pulseSamples...)
+		copy(sample, r.sampleAmplitude(math.Max(0, math.Min(1, amplitude))))
+	}
+}
+
+// renderBlock renders the pulses and the pause of the given block
+func (r *Reader) renderBlock(b block.Block, state *renderState, w sampleWriter) {
+	pulses := b.Pulses()
+	if p, ok := b.(block.PilotTone); ok && r.minPilot > 0 {
+		pulses = shortenPilotTone(pulses, p.PilotPulsesNb(), r.minPilot)
+	}
+	r.renderPulses(pulses, state, w)
+	if t, ok := b.(block.CompatibilityTail); ok && r.tail {
+		r.renderPulses(t.TailPulses(), state, w)
+	}
+	if s, ok := b.(block.Silence); ok && s.EndsWithSilence() {
+		state.pulseOpen = false
+	}
+
+	pauseDuration := b.PauseDuration()
+	if r.maxPause > 0 && pauseDuration > r.maxPause {
+		pauseDuration = r.maxPause
+	}
+	r.renderPause(pauseDuration, state, w)
+}
+
+// shortenPilotTone removes pulses from the beginning of the pilot tone to keep
+// at least minPulses pulses. An even number of pulses is removed so the level
+// of the following pulses is unchanged.
+func shortenPilotTone(pulses []block.Pulse, pilotPulsesNb int, minPulses int) []block.Pulse {
+	if pilotPulsesNb > len(pulses) {
+		pilotPulsesNb = len(pulses)
+	}
+	removed := pilotPulsesNb - minPulses
+	if removed <= 0 {
+		return pulses
 	}
+	removed -= removed % 2
+	return pulses[removed:]
+}
 
-	return samples
+// renderPulses renders the given pulses as audio PCM samples.
+// The level of the pulses is given by their edge applied to the current level.
+func (r *Reader) renderPulses(pulses []block.Pulse, state *renderState, w sampleWriter) {
+	samplesPerSecond := r.speedFactor * float64(r.SamplingRate)
+	for _, pulse := range pulses {
+		state.level, state.pulseOpen = pulse.Level(state.level, state.pulseOpen)
+		// The clock frequency divides last, so whole numbers of samples stay exact
+		r.renderLevel(float64(pulse.Length)*samplesPerSecond/block.ZXClockHz, state, w)
+	}
 }
 
-// pauseToSamples generates a silence as audio PCM samples of the given duration in ms
-func (r *Reader) pauseToSamples(duration int) []byte {
-	nbSamples := duration * (r.SamplingRate / 1000)
-	samples := make([]byte, 0)
-	for i := 0; i < nbSamples; i++ {
-		samples = append(samples, r.sampleValue(false)...)
+// renderPause renders a pause as audio PCM samples of the given duration in ms.
+// As stated by the TZX specification, the last pulse is finished by 1 ms at the
+// level opposite to the current one, then the level goes low.
+func (r *Reader) renderPause(duration int, state *renderState, w sampleWriter) {
+	if duration == 0 {
+		return
+	}
+	if state.pulseOpen {
+		state.level = !state.level
+		r.renderLevel(r.msToSamples(1), state, w)
+		duration--
+		state.pulseOpen = false
+	}
+	state.level = false
+	r.renderLevel(r.msToSamples(duration), state, w)
+}
+
+// renderLevel renders the current level during the given number of samples.
+// The fraction of sample left is carried to the next rendering.
+func (r *Reader) renderLevel(nbSamples float64, state *renderState, w sampleWriter) {
+	offset := state.remainder
+	end := state.remainder + nbSamples
+	wholeSamples := math.Floor(end + samplesEpsilon)
+	state.remainder = math.Max(end-wholeSamples, 0)
+	w(int(wholeSamples), state.level, offset)
+}
+
+// msToSamples returns the number of samples of the given duration in ms
+func (r *Reader) msToSamples(duration int) float64 {
+	return float64(duration) * float64(r.SamplingRate) / 1000
+}
+
+// sampleSize returns the size in bytes of one sample
+func (r *Reader) sampleSize() int64 {
+	return int64(r.bitDepth / 8)
+}
+
+// samplesSize returns the size in bytes of the given number of samples
+func (r *Reader) samplesSize(nbSamples int) int64 {
+	return int64(nbSamples) * r.sampleSize()
+}
+
+// silence fills the given buffer with low level samples
+func (r *Reader) silence(p []byte) {
+	sample := r.sampleValue(false)
+	for i := range p {
+		p[i] = sample[i%len(sample)]
+	}
+}
+
+// sampleCorrection is a smoothing of the amplitude of a sample
+type sampleCorrection struct {
+	pos   int64
+	value float64
+}
+
+// edgeCorrections returns the amplitude corrections of the samples before and
+// after an edge starting at the given fraction of a sample (polyBLEP)
+func edgeCorrections(from bool, to bool, offset float64) (before float64, after float64) {
+	if from == to {
+		return 0, 0
+	}
+	step := 1.0
+	if !to {
+		step = -1.0
+	}
+	return step * (1 - offset) * (1 - offset) / 2, -step * offset * offset / 2
+}
+
+// sampleAmplitude returns the audio PCM sample of the given amplitude, from 0
+// (low level) to 1 (high level)
+func (r *Reader) sampleAmplitude(amplitude float64) []byte {
+	switch r.bitDepth {
+	case 8:
+		return []byte{byte(math.Round(amplitude * 255))}
+	case 16:
+		value := uint16(int16(math.Round(-0x8000 + amplitude*(0x7f00+0x8000))))
+		return []byte{byte(value), byte(value >> 8)}
+	case 24:
+		value := uint32(int32(math.Round(-0x800000 + amplitude*(0x7f0000+0x800000))))
+		return []byte{byte(value), byte(value >> 8), byte(value >> 16)}
+	default: // 32 bit float
+		sample := make([]byte, 4)
+		binary.LittleEndian.PutUint32(sample, math.Float32bits(float32(amplitude*2-1)))
+		return sample
 	}
-	return samples
 }
 
 // sampleValue returns the audio PCM sample equivalent of a low level or high level
 func (r *Reader) sampleValue(level bool) []byte {
-	if r.bitDepth == 8 {
+	switch r.bitDepth {
+	case 8:
 		if !level {
 			return []byte{0}
 		} else {
 			return []byte{255}
 		}
-	} else { // 16 bit
+	case 16:
 		if !level {
 			return []byte{0x00, 0x80}
 		} else {
 			return []byte{0x00, 0x7f}
 		}
+	case 24:
+		if !level {
+			return []byte{0x00, 0x00, 0x80}
+		} else {
+			return []byte{0x00, 0x00, 0x7f}
+		}
+	default: // 32 bit float
+		if !level {
+			return []byte{0x00, 0x00, 0x80, 0xbf} // -1.0
+		} else {
+			return []byte{0x00, 0x00, 0x80, 0x3f} // 1.0
+		}
 	}
 }
@@ -0,0 +1,376 @@
+package tape
+
+import (
+	"bytes"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// levelRun is a sequence of samples at the same level
+type levelRun struct {
+	level   bool
+	samples int
+}
+
+// levelRuns renders the tape at 35000 Hz, which is 100 T-states per sample,
+// and returns the level runs following the leading silence
+func levelRuns(t *testing.T, tape *Tape) []levelRun {
+	t.Helper()
+	r, err := NewReader(tape, ReaderOptions{SamplingRate: 35000, BitDepth: 8, SpeedFactor: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	low := r.sampleValue(false)[0]
+	runs := make([]levelRun, 0)
+	for _, sample := range samples[500*35:] { // 500 ms of leading silence
+		level := sample != low
+		if len(runs) == 0 || runs[len(runs)-1].level != level {
+			runs = append(runs, levelRun{level: level})
+		}
+		runs[len(runs)-1].samples++
+	}
+	return runs
+}
+
+func TestReaderStops(t *testing.T) {
+	tests := []struct {
+		name    string
+		blocks  [][]byte
+		machine Machine
+		stops   []int
+	}{
+		{
+			name:   "zero ms pause",
+			blocks: [][]byte{tzxPureTone(1000, 2), tzxPause(0), tzxPureTone(1000, 2), tzxPause(0)},
+			stops:  []int{1, 3},
+		},
+		{
+			name:   "pause with a duration",
+			blocks: [][]byte{tzxPureTone(1000, 2), tzxPause(10), tzxPureTone(1000, 2)},
+			stops:  []int{},
+		},
+		{
+			name:    "stop the tape if in 48K mode on a 48K",
+			blocks:  [][]byte{tzxPureTone(1000, 2), tzxStopTape48K(), tzxPureTone(1000, 2)},
+			machine: Machine48K,
+			stops:   []int{1},
+		},
+		{
+			name:    "stop the tape if in 48K mode on a 128K",
+			blocks:  [][]byte{tzxPureTone(1000, 2), tzxStopTape48K(), tzxPureTone(1000, 2)},
+			machine: Machine128K,
+			stops:   []int{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tape := newTestTape(t, "test.tzx", tzx(test.blocks...))
+			r, err := NewReader(tape, ReaderOptions{SamplingRate: 44100, BitDepth: 8, SpeedFactor: 1, Machine: test.machine})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The playback stops where the stopping blocks start
+			expected := make([]int64, 0)
+			for _, index := range test.stops {
+				for _, entry := range r.blocksBytes {
+					if entry.blockIndex == index {
+						expected = append(expected, entry.blockByte)
+					}
+				}
+			}
+			stops := make([]int64, 0)
+			for stop := r.NextStop(); stop >= 0; stop = r.NextStop() {
+				stops = append(stops, stop)
+				if _, err := r.Seek(stop, io.SeekStart); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !reflect.DeepEqual(stops, expected) {
+				t.Errorf("stops at %v, expected %v", stops, expected)
+			}
+		})
+	}
+}
+
+func TestLevelContinuity(t *testing.T) {
+	low, high := false, true
+	tests := []struct {
+		name   string
+		blocks [][]byte
+		runs   []levelRun
+	}{
+		{
+			name:   "first pulse of the tape",
+			blocks: [][]byte{tzxPureTone(10000, 3), tzxPause(10)},
+			runs:   []levelRun{{low, 100}, {high, 100}, {low, 100}, {high, 35}, {low, 0}},
+		},
+		{
+			name:   "data after data",
+			blocks: [][]byte{tzxPureTone(10000, 3), tzxPureTone(10000, 2), tzxPause(10)},
+			runs:   []levelRun{{low, 100}, {high, 100}, {low, 100}, {high, 100}, {low, 100}, {high, 35}, {low, 0}},
+		},
+		{
+			name:   "pause then data",
+			blocks: [][]byte{tzxPureTone(10000, 2), tzxPause(10), tzxPureTone(10000, 2), tzxPause(10)},
+			runs:   []levelRun{{low, 100}, {high, 100}, {low, 450}, {high, 100}, {low, 0}},
+		},
+		{
+			name:   "signal level then data",
+			blocks: [][]byte{tzxPureTone(10000, 1), tzxSetSignalLevel(true), tzxPureTone(10000, 2), tzxPause(10)},
+			runs:   []levelRun{{low, 100}, {high, 100}, {low, 100}, {high, 35}, {low, 0}},
+		},
+		{
+			name:   "opposite level symbol after a pause",
+			blocks: [][]byte{tzxPause(10), tzxGeneralizedDataBlock(0, 10000, 3), tzxPause(10)},
+			runs:   []levelRun{{low, 450}, {high, 100}, {low, 100}, {high, 35}, {low, 0}},
+		},
+		{
+			name:   "same level symbol",
+			blocks: [][]byte{tzxPureTone(10000, 2), tzxGeneralizedDataBlock(1, 10000, 2), tzxPause(10)},
+			runs:   []levelRun{{low, 100}, {high, 300}, {low, 0}},
+		},
+		{
+			name:   "force low symbol",
+			blocks: [][]byte{tzxPureTone(10000, 2), tzxGeneralizedDataBlock(2, 10000, 2), tzxPause(10)},
+			runs:   []levelRun{{low, 100}, {high, 100}, {low, 200}, {high, 35}, {low, 0}},
+		},
+		{
+			name:   "force high symbol after a pause",
+			blocks: [][]byte{tzxPause(10), tzxGeneralizedDataBlock(3, 10000, 2), tzxPause(10)},
+			runs:   []levelRun{{low, 350}, {high, 200}, {low, 0}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runs := levelRuns(t, newTestTape(t, "test.tzx", tzx(test.blocks...)))
+			if len(runs) != len(test.runs) {
+				t.Fatalf("level runs %v, expected %v", runs, test.runs)
+			}
+			for i, run := range runs {
+				expected := test.runs[i]
+				// The last run holds the trailing silence
+				if i == len(runs)-1 {
+					run.samples = 0
+				}
+				if run.level != expected.level || run.samples < expected.samples-3 || run.samples > expected.samples+3 {
+					t.Fatalf("level runs %v, expected %v", runs, test.runs)
+				}
+			}
+		})
+	}
+}
+
+func TestCompatibilityTail(t *testing.T) {
+	// Standard Speed Data Block of 2 bytes without pause
+	tape := newTestTape(t, "test.tzx", tzx([]byte{0x10, 0, 0, 2, 0, 0xff, 0x01}))
+
+	runsNb := make(map[bool]int)
+	for _, tail := range []bool{false, true} {
+		r, err := NewReader(tape, ReaderOptions{SamplingRate: 35000, BitDepth: 8, SpeedFactor: 1, CompatibilityTail: tail})
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range samples {
+			if i == 0 || samples[i] != samples[i-1] {
+				runsNb[tail]++
+			}
+		}
+	}
+
+	// Every pulse of the tail toggles the level
+	if runsNb[true]-runsNb[false] != 64 {
+		t.Errorf("%d level runs with the tail, %d without, expected 64 more", runsNb[true], runsNb[false])
+	}
+}
+
+// sequentialSamples renders the blocks of the reader one after the other
+// with a single render state, without the block index
+func sequentialSamples(r *Reader) []byte {
+	samples := make([]byte, 0)
+	write := func(n int, level bool, _ float64) {
+		for i := 0; i < n; i++ {
+			samples = append(samples, r.sampleValue(level)...)
+		}
+	}
+	state := startState
+	r.renderPause(LeadingSilenceDuration, &state, write)
+	for _, entry := range r.blocksBytes {
+		r.renderBlock(r.tape.Blocks[entry.blockIndex], &state, write)
+	}
+	r.renderPause(TrailingSilenceDuration, &state, write)
+	return samples
+}
+
+func TestSeekRead(t *testing.T) {
+	tape := newTestTape(t, "test.tzx", tzx(
+		tzxPureTone(1234, 7),
+		tzxPause(3),
+		tzxSetSignalLevel(true),
+		tzxPureTone(777, 5),
+		tzxGeneralizedDataBlock(1, 999, 3),
+		[]byte{0x10, 2, 0, 2, 0, 0xff, 0x01},
+		tzxPureTone(555, 3),
+	))
+	options := ReaderOptions{SamplingRate: 44100, BitDepth: 16, SpeedFactor: 1}
+
+	r, err := NewReader(tape, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := sequentialSamples(r)
+	if int64(len(full)) != r.Size() {
+		t.Fatalf("%d bytes rendered, expected %d", len(full), r.Size())
+	}
+
+	// Block boundaries, positions around them and in the middle of the blocks
+	positions := []int64{0, r.Size() - 2}
+	for i, entry := range r.blocksBytes {
+		positions = append(positions, entry.blockByte, entry.blockByte-2, entry.blockByte+2)
+		if i < len(r.blocksBytes)-1 {
+			middle := (entry.blockByte + r.blocksBytes[i+1].blockByte) / 2
+			positions = append(positions, middle&^1)
+		}
+	}
+
+	// Positions are visited backward, so the rendered segment never follows
+	// the previous one
+	buf := make([]byte, 1000)
+	for i := len(positions) - 1; i >= 0; i-- {
+		pos := positions[i]
+		if pos < 0 || pos >= r.Size() {
+			continue
+		}
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		n, err := io.ReadFull(r, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf[:n], full[pos:pos+int64(n)]) {
+			t.Errorf("samples read at %d differ from the sequential rendering", pos)
+		}
+		if pos+int64(n) != r.Pos() {
+			t.Errorf("position %d after reading %d bytes at %d", r.Pos(), n, pos)
+		}
+	}
+
+	// The whole stream read from the beginning
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	samples, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(samples, full) {
+		t.Error("samples read differ from the sequential rendering")
+	}
+}
+
+func TestRenderPrecision(t *testing.T) {
+	lengths := []int{667, 735, 855, 1710, 2168, 101, 333, 7777, 12345, 2001}
+	var blocks [][]byte
+	var edges []int64
+	var total int64
+	for i := 0; i < 50; i++ {
+		blocks = append(blocks, tzxPulseSequence(lengths...))
+		for _, length := range lengths {
+			total += int64(length)
+			edges = append(edges, total)
+		}
+	}
+	tape := newTestTape(t, "test.tzx", tzx(blocks...))
+
+	for _, rate := range []int64{44100, 48000} {
+		// Rounded number of samples of the given T-states, in integers
+		samples := func(tStates int64) int64 {
+			return (2*tStates*rate + block.ZXClockHz) / (2 * block.ZXClockHz)
+		}
+
+		r, err := NewReader(tape, ReaderOptions{SamplingRate: int(rate), BitDepth: 8, SpeedFactor: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		leading := rate * LeadingSilenceDuration / 1000
+		trailing := rate * TrailingSilenceDuration / 1000
+		if expected := leading + samples(total) + trailing; r.Size() != expected {
+			t.Errorf("%d Hz: %d samples, expected %d", rate, r.Size(), expected)
+		}
+
+		// The first pulse is low like the leading silence, then every pulse
+		// ends with an edge
+		rendered, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		level := false
+		for i, edge := range edges {
+			pos := leading + samples(edge)
+			level = !level
+			if (rendered[pos-1] != 0) == level || (rendered[pos] != 0) != level {
+				t.Fatalf("%d Hz: no edge %d at sample %d", rate, i, pos)
+			}
+		}
+	}
+}
+
+func TestAcceleratedLoad(t *testing.T) {
+	// Standard speed data block of one 0xff byte: 3223 pilot pulses, sync
+	// pulses and data pulses, then the given pause
+	dataBlock := func(pause int) []byte {
+		return []byte{0x10, byte(pause), byte(pause >> 8), 0x01, 0x00, 0xff}
+	}
+	const syncAndData = 667 + 735 + 16*1710
+
+	tests := []struct {
+		name     string
+		blocks   [][]byte
+		options  ReaderOptions
+		tStates  int64 // of the pulses
+		duration int64 // of the pauses, in ms
+	}{
+		{"speed factor", [][]byte{tzxPureTone(1000, 350)}, ReaderOptions{SpeedFactor: 0.5}, 350000, 0},
+		{"slowing speed factor", [][]byte{tzxPureTone(1000, 350)}, ReaderOptions{SpeedFactor: 2}, 350000, 0},
+		{"pilot tone kept", [][]byte{dataBlock(0)}, ReaderOptions{SpeedFactor: 1}, 3223*2168 + syncAndData, 0},
+		{"pilot tone shortened", [][]byte{dataBlock(0)}, ReaderOptions{SpeedFactor: 1, MinPilotPulses: 101}, 101*2168 + syncAndData, 0},
+		{"pilot tone level kept", [][]byte{dataBlock(0)}, ReaderOptions{SpeedFactor: 1, MinPilotPulses: 100}, 101*2168 + syncAndData, 0},
+		{"pilot tone shorter than the minimum", [][]byte{dataBlock(0)}, ReaderOptions{SpeedFactor: 1, MinPilotPulses: 5000}, 3223*2168 + syncAndData, 0},
+		{"pause kept", [][]byte{tzxPause(2000)}, ReaderOptions{SpeedFactor: 1}, 0, 2000},
+		{"pause limited", [][]byte{tzxPause(2000)}, ReaderOptions{SpeedFactor: 1, MaxPauseDuration: 300}, 0, 300},
+		{"pause shorter than the maximum", [][]byte{tzxPause(200)}, ReaderOptions{SpeedFactor: 1, MaxPauseDuration: 300}, 0, 200},
+		{"data block pause limited", [][]byte{dataBlock(1000)}, ReaderOptions{SpeedFactor: 1, MaxPauseDuration: 300}, 3223*2168 + syncAndData, 300},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.SamplingRate = 35000
+			test.options.BitDepth = 8
+			r, err := NewReader(newTestTape(t, "test.tzx", tzx(test.blocks...)), test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 100 T-states per sample, scaled by the speed factor
+			pulses := int64(math.Round(float64(test.tStates) * test.options.SpeedFactor / 100))
+			expected := pulses + test.duration*35
+			if samples := r.blocksEnd - r.blocksBytes[0].blockByte; samples != expected {
+				t.Errorf("%d samples, expected %d", samples, expected)
+			}
+		})
+	}
+}
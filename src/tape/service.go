@@ -1,7 +1,13 @@
 package tape
 
 import (
+	"fmt"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"github.com/gordonklaus/portaudio"
 	"io"
+	"os"
+	"path/filepath"
+	"regexp"
 	"time"
 )
 
@@ -13,7 +19,7 @@ func NewService() *Service {
 }
 
 // ConvertToWavFile converts the given TZX tape file into an audio PCM WAV file
-func (s *Service) ConvertToWavFile(tzxFile string, outputFile string, samplingRate int, bitDepth int, speedFactor float64) (*time.Duration, error) {
+func (s *Service) ConvertToWavFile(tzxFile string, outputFile string, options ReaderOptions) (*time.Duration, error) {
 	start := time.Now()
 
 	tape, err := NewTape(tzxFile)
@@ -21,12 +27,12 @@ func (s *Service) ConvertToWavFile(tzxFile string, outputFile string, samplingRa
 		return nil, err
 	}
 
-	tapeReader, err := NewReader(tape, samplingRate, bitDepth, speedFactor)
+	tapeReader, err := NewReader(tape, options)
 	if err != nil {
 		return nil, err
 	}
 
-	wavWriter, err := NewWavFileWriter(outputFile, samplingRate, bitDepth)
+	wavWriter, err := NewWavFileWriter(outputFile, options.SamplingRate, options.BitDepth)
 	defer func() {
 		err = wavWriter.Close()
 	}()
@@ -43,6 +49,28 @@ func (s *Service) ConvertToWavFile(tzxFile string, outputFile string, samplingRa
 	return &end, nil
 }
 
+// ConvertToPzxFile converts the given TZX tape file into a PZX tape file.
+// Options related to the audio samples are ignored.
+func (s *Service) ConvertToPzxFile(tzxFile string, outputFile string, options ReaderOptions) (*time.Duration, error) {
+	start := time.Now()
+
+	tape, err := NewTape(tzxFile)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := writePzx(tape, options)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(outputFile, content, 0644); err != nil {
+		return nil, err
+	}
+
+	end := time.Since(start)
+	return &end, nil
+}
+
 // Info returns information about a TZX tape file (version, blocks etc.)
 func (s *Service) Info(tzxFile string) (*TapeInfo, error) {
 	tape, err := NewTape(tzxFile)
@@ -53,19 +81,65 @@ func (s *Service) Info(tzxFile string) (*TapeInfo, error) {
 	return &info, nil
 }
 
+// ExtractCustomInfos writes the payloads of the Custom Info blocks (POKEs,
+// instructions, pictures etc.) of a TZX tape file into the given directory.
+// It returns the names of the written files.
+func (s *Service) ExtractCustomInfos(tzxFile string, outputDir string) ([]string, error) {
+	tape, err := NewTape(tzxFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+
+	fileNameRegex := regexp.MustCompile("[^A-Za-z0-9]+")
+	files := make([]string, 0)
+	for i, b := range tape.Blocks {
+		customInfo, ok := b.(*block.CustomInfo)
+		if !ok {
+			continue
+		}
+		data, extension := customInfo.Payload()
+		fileName := filepath.Join(
+			outputDir,
+			fmt.Sprintf("%03d-%s.%s", i+1, fileNameRegex.ReplaceAllString(customInfo.Identification(), "_"), extension),
+		)
+		if err = os.WriteFile(fileName, data, 0644); err != nil {
+			return nil, err
+		}
+		files = append(files, fileName)
+	}
+
+	return files, nil
+}
+
+// Devices lists the audio host APIs and their output devices
+func (s *Service) Devices() ([]HostApi, error) {
+	if err := portaudio.Initialize(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = portaudio.Terminate()
+	}()
+
+	return outputDevices()
+}
+
 // Play plays a TZX file through audio sound card
-func (s *Service) Play(tzxFile string, samplingRate int, bitDepth int, speedFactor float64) (*Player, error) {
+func (s *Service) Play(tzxFile string, options ReaderOptions, playerOptions PlayerOptions) (*Player, error) {
 	tape, err := NewTape(tzxFile)
 	if err != nil {
 		return nil, err
 	}
 
-	tapeReader, err := NewReader(tape, samplingRate, bitDepth, speedFactor)
+	tapeReader, err := NewReader(tape, options)
 	if err != nil {
 		return nil, err
 	}
 
-	player := NewPlayer(tapeReader)
+	player := NewPlayer(tapeReader, playerOptions)
 	if err = player.Start(); err != nil {
 		return nil, err
 	}
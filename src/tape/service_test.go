@@ -0,0 +1,53 @@
+package tape
+
+import (
+	"bytes"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestServiceExtractCustomInfos(t *testing.T) {
+	screen := bytes.Repeat([]byte{0xaa}, block.SpectrumScreenSize)
+	tzxFile := writeTestFile(t, "test.tzx", tzx(
+		tzxCustomInfo("Instructions", []byte("LOAD \"\"\rRUN")),
+		tzxPureTone(1000, 2),
+		tzxCustomInfo("Spectrum Screen", append([]byte{5, 'T', 'i', 't', 'l', 'e', 1}, screen...)),
+		[]byte("ZXTape!\x1a\x01\x14"),
+		tzxCustomInfo("Picture", []byte{1, 0, 0xff, 0xd8}),
+		tzxCustomInfo("Hardware info", []byte{1, 2}),
+	))
+	dir := filepath.Join(t.TempDir(), "infos")
+
+	files, err := NewService().ExtractCustomInfos(tzxFile, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		name string
+		data []byte
+	}{
+		{"001-Instructions.txt", []byte("LOAD \"\"\nRUN")},
+		{"003-Spectrum_Screen.scr", screen},
+		{"005-Picture.jpg", []byte{0xff, 0xd8}},
+		{"006-Hardware_info.bin", []byte{1, 2}},
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("extracted files %v, expected %d files", files, len(expected))
+	}
+	for i, file := range expected {
+		if files[i] != filepath.Join(dir, file.name) {
+			t.Errorf("extracted file %s, expected %s", files[i], file.name)
+			continue
+		}
+		data, err := os.ReadFile(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, file.data) {
+			t.Errorf("%s holds %d bytes, expected %d", file.name, len(data), len(file.data))
+		}
+	}
+}
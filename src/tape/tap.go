@@ -0,0 +1,45 @@
+package tape
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"io"
+	"os"
+	"strings"
+)
+
+// TapDefaultPause is the pause in ms after each block of a TAP file
+const TapDefaultPause = 1000
+
+// readTap reads the records of a Spectrum TAP file. Each record is the data
+// of a Standard Speed Data Block, preceded by its length. Commodore TAP files,
+// which share the extension, are recognized by their signature.
+func (t *Tape) readTap(tapFile *os.File) error {
+	content, err := io.ReadAll(tapFile)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(string(content), C64TapSignature) {
+		t.Format = FormatC64Tap
+		return t.readC64Tap(content)
+	}
+
+	for pos := 0; pos < len(content); {
+		if pos+2 > len(content) {
+			return errors.New("not a valid TAP file (truncated record length)")
+		}
+		length := int(binary.LittleEndian.Uint16(content[pos : pos+2]))
+		pos += 2
+		if pos+length > len(content) {
+			return errors.New("not a valid TAP file (truncated record)")
+		}
+		if length > 0 {
+			t.Blocks = append(t.Blocks, block.NewStandardSpeedDataBlock(content[pos:pos+length], TapDefaultPause))
+		}
+		pos += length
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package tape
+
+import (
+	"github.com/TiBeN/tzx-player/tape/block"
+	"reflect"
+	"testing"
+)
+
+func TestReadTap(t *testing.T) {
+	dataBlock := "Standard Speed Data Block"
+	tests := []struct {
+		name    string
+		content []byte
+		blocks  []string
+		err     string
+	}{
+		{"empty", []byte{}, []string{}, ""},
+		{"one record", []byte{3, 0, 0x00, 0x01, 0x02}, []string{dataBlock}, ""},
+		{"two records", []byte{2, 0, 0x00, 0xff, 3, 0, 0xff, 0x01, 0x02}, []string{dataBlock, dataBlock}, ""},
+		{"empty record", []byte{0, 0, 2, 0, 0xff, 0x01}, []string{dataBlock}, ""},
+		{"truncated length", []byte{2, 0, 0x00, 0xff, 3}, nil, "not a valid TAP file (truncated record length)"},
+		{"truncated record", []byte{4, 0, 0x00, 0xff}, nil, "not a valid TAP file (truncated record)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tape, ok := readTestTape(t, "test.tap", test.content, test.err)
+			if !ok {
+				return
+			}
+			if tape.Format != FormatTap {
+				t.Errorf("format %s", tape.Format)
+			}
+			if names := blockNames(tape); !reflect.DeepEqual(names, test.blocks) {
+				t.Fatalf("blocks %v, expected %v", names, test.blocks)
+			}
+			for _, b := range tape.Blocks {
+				if b.PauseDuration() != TapDefaultPause {
+					t.Errorf("pause %d ms", b.PauseDuration())
+				}
+			}
+		})
+	}
+}
+
+func TestTapPulses(t *testing.T) {
+	// Header and data flags select the pilot tone length
+	tape := newTestTape(t, "test.tap", []byte{2, 0, 0x00, 0x80, 2, 0, 0xff, 0x01})
+	tests := []struct {
+		pilotPulsesNb int
+		lastPulses    []int
+	}{
+		{block.StandardHeaderPilotToneLength, []int{block.StandardZeroBitPulseLength, block.StandardZeroBitPulseLength}},
+		{block.StandardDataPilotToneLength, []int{block.StandardZeroBitPulseLength, block.StandardOneBitPulseLength, block.StandardOneBitPulseLength}},
+	}
+	for i, test := range tests {
+		pulses := tape.Blocks[i].Pulses()
+		if expected := test.pilotPulsesNb + 2 + 16*2; len(pulses) != expected {
+			t.Fatalf("block %d: %d pulses, expected %d", i+1, len(pulses), expected)
+		}
+		for j, pulse := range pulses[:test.pilotPulsesNb] {
+			if pulse.Length != block.StandardPilotPulseLength {
+				t.Fatalf("block %d: pilot pulse %d of %d T-states", i+1, j+1, pulse.Length)
+			}
+		}
+		last := pulses[len(pulses)-len(test.lastPulses):]
+		for j, length := range test.lastPulses {
+			if last[j].Length != length {
+				t.Errorf("block %d: last pulses %v, expected %v", i+1, last, test.lastPulses)
+				break
+			}
+		}
+	}
+}
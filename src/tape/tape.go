@@ -6,15 +6,26 @@ import (
 	"github.com/TiBeN/tzx-player/tape/block"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 const TzxSignature = "ZXTape!"
 
+// Tape file formats
+const FormatTzx = "TZX"
+const FormatTap = "TAP"
+const FormatCsw = "CSW"
+const FormatPzx = "PZX"
+const FormatC64Tap = "C64 TAP"
+const FormatUef = "UEF"
+
 type Tape struct {
 	Header   Header
 	Blocks   []block.Block
 	FileName string
+	Format   string
 }
 
 type Header struct {
@@ -22,15 +33,51 @@ type Header struct {
 	MinorVersion int
 }
 
-func NewTape(tzxFile string) (*Tape, error) {
-	f, err := os.Open(tzxFile)
+// NewTape reads a tape file. The format of the file is given by its
+// extension: .tap files are Spectrum or Commodore TAP files, .csw files are CSW files,
+// .pzx files are PZX files, .uef files are UEF files, other files are TZX
+// files.
+func NewTape(tapeFile string) (*Tape, error) {
+	f, err := os.Open(tapeFile)
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		_ = f.Close()
+	}()
 
 	tape := Tape{
-		FileName: tzxFile,
+		FileName: tapeFile,
+		Format:   FormatTzx,
 	}
+
+	switch strings.ToLower(filepath.Ext(tapeFile)) {
+	case ".tap":
+		tape.Format = FormatTap
+		if err := tape.readTap(f); err != nil {
+			return nil, err
+		}
+		return &tape, nil
+	case ".csw":
+		tape.Format = FormatCsw
+		if err := tape.readCsw(f); err != nil {
+			return nil, err
+		}
+		return &tape, nil
+	case ".pzx":
+		tape.Format = FormatPzx
+		if err := tape.readPzx(f); err != nil {
+			return nil, err
+		}
+		return &tape, nil
+	case ".uef":
+		tape.Format = FormatUef
+		if err := tape.readUef(f); err != nil {
+			return nil, err
+		}
+		return &tape, nil
+	}
+
 	if err := tape.readHeader(f); err != nil {
 		return nil, err
 	}
@@ -44,22 +91,33 @@ func NewTape(tzxFile string) (*Tape, error) {
 }
 
 type TapeInfo struct {
+	Format string
+
+	// Version is the version of the file format, empty if the format has no version
 	Version string
-	Blocks  [][][]string
+
+	Blocks [][][]string
 }
 
 // Info returns information about the tape
 func (t *Tape) Info() TapeInfo {
 	info := TapeInfo{
-		Version: fmt.Sprintf("%d.%d", t.Header.MajorVersion, t.Header.MinorVersion),
+		Format: t.Format,
+	}
+	switch t.Format {
+	case FormatTap:
+	case FormatC64Tap:
+		info.Version = strconv.Itoa(t.Header.MajorVersion)
+	default:
+		info.Version = fmt.Sprintf("%d.%d", t.Header.MajorVersion, t.Header.MinorVersion)
 	}
 
 	for i, blk := range t.Blocks {
-		blockInfo := [][]string{
-			{"Block Number", strconv.Itoa(i + 1)},
-			{"Block ID", fmt.Sprintf("%x", blk.Id())},
-			{"Block Type", blk.Name()},
+		blockInfo := [][]string{{"Block Number", strconv.Itoa(i + 1)}}
+		if blk.Id() != block.NoId {
+			blockInfo = append(blockInfo, []string{"Block ID", fmt.Sprintf("%x", blk.Id())})
 		}
+		blockInfo = append(blockInfo, []string{"Block Type", blk.Name()})
 		for _, param := range blk.Info() {
 			blockInfo = append(blockInfo, param)
 		}
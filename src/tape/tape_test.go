@@ -0,0 +1,168 @@
+package tape
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestFile writes a file with the given name and content into a
+// temporary directory and returns its path
+func writeTestFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newTestTape writes a tape file with the given name and content into a
+// temporary directory, then reads it
+func newTestTape(t *testing.T, name string, content []byte) *Tape {
+	t.Helper()
+	tape, err := NewTape(writeTestFile(t, name, content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tape
+}
+
+// readTestTape writes a tape file with the given name and content into a
+// temporary directory, then reads it. The reading must fail with an error
+// containing expectedErr, or succeed if it is empty. It returns false when the
+// reading failed as expected.
+func readTestTape(t *testing.T, name string, content []byte, expectedErr string) (*Tape, bool) {
+	t.Helper()
+	tape, err := NewTape(writeTestFile(t, name, content))
+	if expectedErr != "" {
+		if err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Fatalf("expected error containing %q, got %v", expectedErr, err)
+		}
+		return nil, false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tape, true
+}
+
+// blockNames returns the names of the blocks of the given tape
+func blockNames(tape *Tape) []string {
+	names := make([]string, 0)
+	for _, b := range tape.Blocks {
+		names = append(names, b.Name())
+	}
+	return names
+}
+
+// tzx returns the content of a TZX file made of the given blocks
+func tzx(blocks ...[]byte) []byte {
+	content := []byte("ZXTape!\x1a\x01\x14")
+	for _, b := range blocks {
+		content = append(content, b...)
+	}
+	return content
+}
+
+// tzxPause returns a Pause block of the given duration in ms
+func tzxPause(duration int) []byte {
+	return binary.LittleEndian.AppendUint16([]byte{0x20}, uint16(duration))
+}
+
+// tzxPureTone returns a Pure Tone block
+func tzxPureTone(pulseLength int, pulsesNb int) []byte {
+	b := binary.LittleEndian.AppendUint16([]byte{0x12}, uint16(pulseLength))
+	return binary.LittleEndian.AppendUint16(b, uint16(pulsesNb))
+}
+
+// tzxJump returns a Jump to block block
+func tzxPulseSequence(pulsesLengths ...int) []byte {
+	b := []byte{0x13, byte(len(pulsesLengths))}
+	for _, length := range pulsesLengths {
+		b = binary.LittleEndian.AppendUint16(b, uint16(length))
+	}
+	return b
+}
+
+func tzxJump(offset int) []byte {
+	return binary.LittleEndian.AppendUint16([]byte{0x23}, uint16(int16(offset)))
+}
+
+// tzxLoopStart returns a Loop start block
+func tzxLoopStart(repetitions int) []byte {
+	return binary.LittleEndian.AppendUint16([]byte{0x24}, uint16(repetitions))
+}
+
+// tzxLoopEnd returns a Loop end block
+func tzxLoopEnd() []byte {
+	return []byte{0x25}
+}
+
+// tzxCallSequence returns a Call sequence block
+func tzxCallSequence(offsets ...int) []byte {
+	b := binary.LittleEndian.AppendUint16([]byte{0x26}, uint16(len(offsets)))
+	for _, offset := range offsets {
+		b = binary.LittleEndian.AppendUint16(b, uint16(int16(offset)))
+	}
+	return b
+}
+
+// tzxReturnFromSequence returns a Return from sequence block
+func tzxReturnFromSequence() []byte {
+	return []byte{0x27}
+}
+
+// tzxSelect returns a Select block with one entry per given offset
+func tzxSelect(offsets ...int) []byte {
+	body := []byte{byte(len(offsets))}
+	for i, offset := range offsets {
+		description := fmt.Sprintf("Entry %d", i+1)
+		body = binary.LittleEndian.AppendUint16(body, uint16(int16(offset)))
+		body = append(body, byte(len(description)))
+		body = append(body, description...)
+	}
+	b := binary.LittleEndian.AppendUint16([]byte{0x28}, uint16(len(body)))
+	return append(b, body...)
+}
+
+// tzxStopTape48K returns a Stop the tape if in 48K mode block
+func tzxStopTape48K() []byte {
+	return []byte{0x2A, 0, 0, 0, 0}
+}
+
+// tzxSetSignalLevel returns a Set Signal Level block
+func tzxSetSignalLevel(high bool) []byte {
+	level := byte(0)
+	if high {
+		level = 1
+	}
+	return []byte{0x2B, 1, 0, 0, 0, level}
+}
+
+// tzxGeneralizedDataBlock returns a Generalized Data Block with a pilot
+// made of the given number of repetitions of a single pulse symbol with the
+// given polarity flags, and no data
+func tzxGeneralizedDataBlock(flags byte, pulseLength int, repetitions int) []byte {
+	body := binary.LittleEndian.AppendUint16(nil, 0) // Pause
+	body = binary.LittleEndian.AppendUint32(body, 1) // Pilot symbols number
+	body = append(body, 1, 1)                        // Max pulses, alphabet size
+	body = binary.LittleEndian.AppendUint32(body, 0) // Data symbols number
+	body = append(body, 0, 0)                        // Max pulses, alphabet size
+	body = append(body, flags)                       // Symbol definition
+	body = binary.LittleEndian.AppendUint16(body, uint16(pulseLength))
+	body = append(body, 0) // Pilot stream
+	body = binary.LittleEndian.AppendUint16(body, uint16(repetitions))
+	b := binary.LittleEndian.AppendUint32([]byte{0x19}, uint32(len(body)))
+	return append(b, body...)
+}
+
+// tzxCustomInfo returns a Custom Info block
+func tzxCustomInfo(identification string, info []byte) []byte {
+	b := append([]byte{0x35}, fmt.Sprintf("%-16s", identification)...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(info)))
+	return append(b, info...)
+}
@@ -0,0 +1,80 @@
+package tape
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"io"
+	"os"
+	"strings"
+)
+
+const UefSignature = "UEF File!\x00"
+
+// readUef reads the chunks of an UEF file, which may be gzip compressed.
+// Origin information chunks are read as Archive Info blocks and position
+// markers as Text Description blocks.
+func (t *Tape) readUef(uefFile *os.File) error {
+	content, err := io.ReadAll(uefFile)
+	if err != nil {
+		return err
+	}
+
+	if len(content) >= 2 && content[0] == 0x1f && content[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return err
+		}
+		if content, err = io.ReadAll(gzipReader); err != nil {
+			return err
+		}
+	}
+
+	if len(content) < 12 || string(content[0:10]) != UefSignature {
+		return errors.New("not a valid UEF file (no UEF signature in header)")
+	}
+	t.Header = Header{
+		MajorVersion: int(content[11]),
+		MinorVersion: int(content[10]),
+	}
+
+	encoding := block.UefEncoding{
+		BaseFrequency: block.UefDefaultBaseFrequency,
+		BaudRate:      block.UefDefaultBaudRate,
+	}
+	for pos := 12; pos < len(content); {
+		if pos+6 > len(content) {
+			return errors.New("not a valid UEF file (truncated chunk header)")
+		}
+		chunkId := binary.LittleEndian.Uint16(content[pos : pos+2])
+		size := int(binary.LittleEndian.Uint32(content[pos+2 : pos+6]))
+		pos += 6
+		if pos+size > len(content) {
+			return errors.New("not a valid UEF file (truncated chunk)")
+		}
+		data := content[pos : pos+size]
+		pos += size
+
+		var b block.Block
+		switch chunkId {
+		case block.UefOriginInformation:
+			b = block.NewArchiveInfo([]block.Text{block.NewText(0x08, uefString(data))})
+		case block.UefPositionMarker:
+			b = block.NewTextDescription(uefString(data))
+		default:
+			if b, err = block.NewUefChunk(chunkId, data, &encoding); err != nil {
+				return err
+			}
+		}
+		t.Blocks = append(t.Blocks, b)
+	}
+
+	return nil
+}
+
+// uefString decodes a null terminated UEF string
+func uefString(data []byte) string {
+	return strings.SplitN(string(data), "\x00", 2)[0]
+}
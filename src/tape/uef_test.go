@@ -0,0 +1,131 @@
+package tape
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"github.com/TiBeN/tzx-player/tape/block"
+	"reflect"
+	"testing"
+)
+
+// uefChunk returns an UEF chunk with the given id and content
+func uefChunk(chunkId uint16, data []byte) []byte {
+	c := binary.LittleEndian.AppendUint16(nil, chunkId)
+	c = binary.LittleEndian.AppendUint32(c, uint32(len(data)))
+	return append(c, data...)
+}
+
+// uef returns the content of an UEF 0.10 file made of the given chunks
+func uef(chunks ...[]byte) []byte {
+	content := append([]byte(UefSignature), 10, 0)
+	for _, c := range chunks {
+		content = append(content, c...)
+	}
+	return content
+}
+
+// gzipped returns the given content gzip compressed
+func gzipped(content []byte) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	_, _ = w.Write(content)
+	_ = w.Close()
+	return b.Bytes()
+}
+
+func TestReadUef(t *testing.T) {
+	chunks := [][]byte{
+		uefChunk(block.UefOriginInformation, []byte("MakeUEF\x00")),
+		uefChunk(block.UefCarrierTone, []byte{3, 0}),
+		uefChunk(block.UefImplicitDataBlock, []byte{0x01}),
+		uefChunk(block.UefIntegerGap, []byte{0xb0, 0x04}),
+		uefChunk(block.UefPositionMarker, []byte("Side A\x00")),
+		uefChunk(block.UefDataEncodingChange, []byte{0x2c, 0x01}),
+		uefChunk(block.UefImplicitDataBlock, []byte{0x01}),
+	}
+	names := []string{
+		"Archive Info",
+		"UEF Carrier tone",
+		"UEF Implicit start/stop bit tape data block",
+		"UEF Integer gap",
+		"Text Description",
+		"UEF Data encoding format change",
+		"UEF Implicit start/stop bit tape data block",
+	}
+
+	tests := []struct {
+		name    string
+		content []byte
+		blocks  []string
+		err     string
+	}{
+		{"empty", uef(), []string{}, ""},
+		{"chunks", uef(chunks...), names, ""},
+		{"gzip", gzipped(uef(chunks...)), names, ""},
+		{"unknown chunk", uef(uefChunk(0x0200, []byte{1, 2})), []string{"UEF Unknown chunk"}, ""},
+		{"no signature", []byte("UEF File?\x00\x0a\x00"), nil, "not a valid UEF file (no UEF signature in header)"},
+		{"truncated chunk header", append(uef(), 0x10, 0x01, 2), nil, "not a valid UEF file (truncated chunk header)"},
+		{"truncated chunk", uef(uefChunk(block.UefCarrierTone, []byte{3, 0})[:7]), nil, "not a valid UEF file (truncated chunk)"},
+		{"truncated carrier tone", uef(uefChunk(block.UefCarrierTone, []byte{3})), nil, "uef chunk 0110: truncated chunk"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tape, ok := readTestTape(t, "test.uef", test.content, test.err)
+			if !ok {
+				return
+			}
+			if tape.Format != FormatUef {
+				t.Errorf("format %s", tape.Format)
+			}
+			if tape.Header.MajorVersion != 0 || tape.Header.MinorVersion != 10 {
+				t.Errorf("version %d.%d", tape.Header.MajorVersion, tape.Header.MinorVersion)
+			}
+			if names := blockNames(tape); !reflect.DeepEqual(names, test.blocks) {
+				t.Errorf("blocks %v, expected %v", names, test.blocks)
+			}
+		})
+	}
+}
+
+func TestUefPulses(t *testing.T) {
+	tape := newTestTape(t, "test.uef", uef(
+		uefChunk(block.UefCarrierTone, []byte{3, 0}),
+		uefChunk(block.UefImplicitDataBlock, []byte{0x01}),
+		uefChunk(block.UefIntegerGap, []byte{0xb0, 0x04}),
+		uefChunk(block.UefDataEncodingChange, []byte{0x2c, 0x01}),
+		uefChunk(block.UefImplicitDataBlock, []byte{0x01}),
+	))
+
+	tests := []struct {
+		pulsesNb    int
+		firstPulses []int
+		silence     bool
+	}{
+		// 3 cycles at 2400 Hz, rounded from the end of the pulses
+		{6, []int{729, 729, 730, 729, 729, 729}, false},
+		// Start bit, 8 data bits and stop bit
+		{2 + 4 + 7*2 + 4, []int{1458, 1459, 729, 729, 729, 729, 1459, 1458}, false},
+		// 1200 cycles at 2400 Hz
+		{1, []int{1750000}, true},
+		{0, []int{}, false},
+		// At 300 bauds, bits are four times longer
+		{4 * (2 + 4 + 7*2 + 4), []int{1458, 1459, 1458, 1458}, false},
+	}
+	for i, test := range tests {
+		b := tape.Blocks[i]
+		pulses := b.Pulses()
+		if len(pulses) != test.pulsesNb {
+			t.Fatalf("block %d: %d pulses, expected %d", i+1, len(pulses), test.pulsesNb)
+		}
+		for j, length := range test.firstPulses {
+			if pulses[j].Length != length {
+				t.Errorf("block %d pulse %d: %d T-states, expected %d", i+1, j+1, pulses[j].Length, length)
+			}
+		}
+		if s := b.(block.Silence); s.EndsWithSilence() != test.silence {
+			t.Errorf("block %d: silence %v", i+1, s.EndsWithSilence())
+		}
+	}
+}
@@ -76,7 +76,10 @@ func (w *WavFileWriter) GenerateHeader() []byte {
 	blocSize := []byte{0x10, 0x00, 0x00, 0x00}
 	copy(header[16:20], blocSize)
 
-	audioFormat := []byte{0x01, 0x00}
+	audioFormat := []byte{0x01, 0x00} // PCM
+	if w.BitDepth == FloatBitDepth {
+		audioFormat = []byte{0x03, 0x00} // IEEE float
+	}
 	copy(header[20:22], audioFormat)
 
 	channelNb := []byte{0x01, 0x00}